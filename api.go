@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"reflect"
 	"regexp"
 	"sort"
@@ -51,12 +52,16 @@ type Api struct {
 	DocumentRef         string     `json:"documentRef"`
 }
 
-// GetFirstExample 获取第一个example 模板中有使用
+// GetFirstExample 获取第一个example,没有时根据参数生成模拟案例 模板中有使用
 func (api Api) GetFirstExample() (example *Example) {
 	if len(api.Examples) > 0 {
 		return api.Examples[0]
 	}
-	return &Example{}
+	mockExample, err := api.MockExample(MockSeedDefault)
+	if err != nil {
+		return &Example{}
+	}
+	return &mockExample
 }
 
 func (api *Api) NewExample(request any, response any) (example *Example) {
@@ -138,57 +143,22 @@ func (api Api) TitleOrDescription() (titleOrDescription string) {
 
 }
 
+// CURLExample 使用默认环境生成 curl 命令,其它语言见 Snippet
 func (api Api) CURLExample() (curlExample string, err error) {
-	var w bytes.Buffer
-	w.WriteString("curl ")
-	u := url.URL{
-		Scheme:   "",
-		Host:     "",
-		Path:     api.Path,
-		RawPath:  api.Path,
-		RawQuery: api.Query.Encode(),
-	}
-	firstU := api.Service.Servers.GetFirst()
-	if firstU.URL != "" {
-		tu, err := url.Parse(firstU.URL)
-		if err != nil {
-			return "", err
-		}
-		u.Scheme = tu.Scheme
-		u.Host = tu.Host
-		if firstU.Proxy != "" {
-			w.WriteString(fmt.Sprintf(` -x%s `, firstU.Proxy))
-		}
-	}
-
-	w.WriteString(fmt.Sprintf("-X%s", api.Method))
+	return api.CURLExampleWithEnvironment("")
+}
 
-	for _, h := range api.RequestHeader {
-		value := h.Example
-		if value == "" {
-			value = h.Default
-		}
-		w.WriteString(fmt.Sprintf(` -H '%s: %s'`, h.Name, value))
-	}
-	contentType := api.RequestHeader.ContentType()
-	if contentType == "" {
-		contentType = Header_Value_Content_Type_Json
+// CURLExampleWithEnvironment 使用指定环境生成 curl 命令,environment 为空时使用默认环境
+func (api Api) CURLExampleWithEnvironment(environment string) (curlExample string, err error) {
+	server, err := api.GetServer(environment)
+	if err != nil {
+		return "", err
 	}
-	switch contentType {
-	case Header_Value_Content_Type_Json:
-		lineschema := api.RequestBody.Lineschema("", false)
-		jsonExample, err := lineschema.JsonExample()
-		if err != nil {
-			return "", err
-		}
-		if jsonExample != "" {
-			w.WriteString(fmt.Sprintf(` -d'%s' `, jsonExample))
-		}
+	snippet, err := api.Snippet(LANGUAGE_BASH, server, Example{})
+	if err != nil {
+		return "", err
 	}
-
-	w.WriteString(fmt.Sprintf(` '%s'`, u.String()))
-	curlExample = w.String()
-	return curlExample, nil
+	return snippet.Code, nil
 }
 
 func (api *Api) Json() (apiJson string, err error) {
@@ -406,15 +376,39 @@ func (q *Query) Add(parameters ...Parameter) {
 	*q = Query(p)
 }
 
+// GetServer 获取指定环境的服务器,environment 为空时返回默认环境
+func (api Api) GetServer(environment string) (server Server, err error) {
+	if api.Service == nil {
+		if environment != "" {
+			err = errors.WithMessagef(ERROR_NOT_FOUND_SERVER, "environment:%s", environment)
+		}
+		return server, err
+	}
+	return api.Service.Servers.GetByEnvironment(environment)
+}
+
 func (api *Api) Example() (example Example, err error) {
+	return api.ExampleWithEnvironment("")
+}
+
+// ExampleWithEnvironment 使用指定环境的地址、代理生成案例,environment 为空时使用默认环境
+func (api *Api) ExampleWithEnvironment(environment string) (example Example, err error) {
 
 	summary := api.Summary
 	if summary == "" {
 		summary = api.Description
 	}
 
-	server := api.Service.Servers.GetFirst()
-	urlObj, _ := url.Parse(server.URL)
+	server, err := api.GetServer(environment)
+	if err != nil {
+		return example, err
+	}
+	resolver := api.VariableResolver(server, Example{})
+	server.URL, server.Proxy = resolver.Resolve(server.URL), resolver.Resolve(server.Proxy)
+	urlObj, err := url.Parse(server.URL)
+	if err != nil {
+		return example, err
+	}
 	urlValues := urlObj.Query()
 	for _, query := range api.Query {
 		key := query.Name
@@ -436,17 +430,19 @@ func (api *Api) Example() (example Example, err error) {
 		return example, err
 	}
 	example = Example{
-		Method:            api.Method,
-		Title:             api.Title,
-		Summary:           summary,
-		Proxy:             server.Proxy,
-		URL:               urlObj.String(),
-		Headers:           api.RequestHeader.ToMap(),
-		ContentType:       api.RequestHeader.ContentType(),
-		RequestBody:       requestBody,
-		Response:          response,
-		RequestPreScript:  api.Service.RequestPreScript,
-		RequestPostScript: api.Service.RequestPostScript,
+		Method:      api.Method,
+		Title:       api.Title,
+		Summary:     summary,
+		Proxy:       server.Proxy,
+		URL:         urlObj.String(),
+		Headers:     api.RequestHeader.ToMap(),
+		ContentType: api.RequestHeader.ContentType(),
+		RequestBody: requestBody,
+		Response:    response,
+		Variables:   server.Variables, // 案例使用所选环境的变量
+	}
+	if api.Service != nil {
+		example.RequestPreScript, example.RequestPostScript = api.Service.RequestPreScript, api.Service.RequestPostScript
 	}
 	return
 }
@@ -462,10 +458,34 @@ type Variable struct {
 	Name        string `json:"name"`
 	Value       string `json:"value"`
 	Description string `json:"description"`
+	// 发送请求时从该环境变量读取值(存在时覆盖 Value),密钥等不写入文档
+	Env string `json:"env,omitempty"`
 }
 
 type Variables []Variable
 
+func (v Variables) ToMap() (m map[string]string) {
+	m = make(map[string]string, len(v))
+	for _, variable := range v {
+		m[variable.Name] = variable.Value
+	}
+	return m
+}
+
+// ToMapWithEnv 同 ToMap,设置了 Env 且环境变量存在时取环境变量值
+func (v Variables) ToMapWithEnv() (m map[string]string) {
+	m = v.ToMap()
+	for _, variable := range v {
+		if variable.Env == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(variable.Env); ok {
+			m[variable.Name] = value
+		}
+	}
+	return m
+}
+
 func (v *Variables) Json() (jsonStr string, err error) {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -518,7 +538,13 @@ func (v *Navigates) Json() (jsonStr string, err error) {
 }
 
 const (
-	LANGUAGE_BASH = "bash"
+	LANGUAGE_BASH       = "bash"
+	LANGUAGE_HTTPIE     = "httpie"
+	LANGUAGE_JAVASCRIPT = "javascript"
+	LANGUAGE_GO         = "go"
+	LANGUAGE_PYTHON     = "python"
+	LANGUAGE_PHP        = "php"
+	LANGUAGE_TENGO      = "tengo"
 )
 
 type LanguageAlias [][]string
@@ -527,6 +553,7 @@ type LanguageAlias [][]string
 var LanguageAliasDefault = LanguageAlias{
 	{"bash"},
 	{"sh"},
+	{"httpie"},
 	{"javascript", "js"},
 	{"go", "golang"},
 	{"php"},
@@ -669,6 +696,8 @@ type Example struct {
 	TestScript string `json:"testScript,omitempty"`
 	// 请求体
 	Response string `json:"response,omitempty"`
+	// 案例变量,优先级高于服务器、服务变量
+	Variables Variables `json:"variables,omitempty"`
 }
 
 func (example *Example) SetRequestBody(request any) *Example {
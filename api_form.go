@@ -11,12 +11,31 @@ import (
 )
 
 func NewHtmxForm(api Api) HtmxForm {
+	server, _ := api.GetServer("")
+	return newHtmxForm(api, server, api.Path)
+}
+
+// NewHtmxFormWithEnvironment 表单请求发送到指定环境的服务器地址,变量使用该环境的变量
+func NewHtmxFormWithEnvironment(api Api, environment string) (htmxForm HtmxForm, err error) {
+	server, err := api.GetServer(environment)
+	if err != nil {
+		return htmxForm, err
+	}
+	action := fmt.Sprintf("%s/%s", strings.TrimRight(server.URL, "/"), strings.TrimLeft(api.Path, "/"))
+	return newHtmxForm(api, server, action), nil
+}
+
+func newHtmxForm(api Api, server Server, action string) HtmxForm {
+	resolver := api.VariableResolver(server, Example{})
+	resolver.Builtins = nil // 动态变量保留占位符,由调试代理发送请求时替换,页面内容保持稳定
 	return HtmxForm{
 		ApiForm: ApiForm{
-			api:    api,
-			Title:  api.TitleOrDescription(),
-			Action: api.Path,
-			Method: api.Method,
+			api:         api,
+			Title:       api.TitleOrDescription(),
+			Action:      resolver.Resolve(action),
+			Method:      api.Method,
+			Environment: server.GetEnvironment(),
+			Resolver:    resolver,
 		},
 		HxTarget: "#response-data",
 		HxExt:    "jsonpretty",
@@ -34,6 +53,10 @@ type ApiForm struct {
 	Action string `json:"action"`
 	Method string `json:"method"`
 	Title  string `json:"title"`
+	// 当前环境
+	Environment string `json:"environment"`
+	// 替换参数默认值、示例值中的 {{name}} 变量
+	Resolver *VariableResolver `json:"-"`
 }
 
 func (htmxForm HtmxForm) String() (html string) {
@@ -50,6 +73,7 @@ func (htmxForm HtmxForm) Html() (html htmlgo.HTML) {
 	attrs = append(attrs, attributes.Method(strings.ToUpper(htmxForm.Method)))
 	htmls := make([]htmlgo.HTML, 0)
 	for _, p := range htmxForm.api.RequestBody {
+		p.Default, p.Example = htmxForm.Resolver.Resolve(p.Default), htmxForm.Resolver.Resolve(p.Example)
 		html := Parameter2FormChidren(p)
 		htmls = append(htmls, html)
 	}
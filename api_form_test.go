@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"github.com/suifengpiao14/apidocbuilder"
 )
 
@@ -14,3 +15,17 @@ func TestA(t *testing.T) {
 	fmt.Println(ht)
 
 }
+
+func TestHtmxFormWithoutEnvSecret(t *testing.T) {
+	t.Setenv("ZZ_FORM_SECRET", "s3cret")
+	service := apidocbuilder.Service{Name: "user", Variables: apidocbuilder.Variables{{Name: "token", Value: "demo", Env: "ZZ_FORM_SECRET"}}}
+	service.AddServer(apidocbuilder.Server{Name: "dev", URL: "http://api.com/{{$env.ZZ_FORM_SECRET}}"})
+	api := apidocbuilder.Api{Name: "add", Method: "POST", Path: "/add", Service: &service, RequestBody: apidocbuilder.Parameters{
+		{Fullname: "token", Type: "string", Default: "{{token}}"},
+	}}
+	form, err := apidocbuilder.NewHtmxFormWithEnvironment(api, "dev")
+	require.NoError(t, err)
+	html := form.String()
+	require.NotContains(t, html, "s3cret")
+	require.Equal(t, "demo", form.Resolver.Resolve("{{token}}")) // 默认值、示例值使用变量的默认值
+}
@@ -0,0 +1,67 @@
+package apidocbuilder
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MatchPath 匹配请求路径,支持 {id}、:id 两种路径参数写法,返回路径参数值和静态片段数量(用于多个接口同时匹配时取最精确的)
+func (api Api) MatchPath(path string) (pathParams map[string]string, staticCount int, ok bool) {
+	patternSegments := splitPath(api.Path)
+	segments := splitPath(path)
+	if len(patternSegments) != len(segments) {
+		return nil, 0, false
+	}
+	pathParams = make(map[string]string)
+	for i, pattern := range patternSegments {
+		name := ""
+		switch {
+		case strings.HasPrefix(pattern, "{") && strings.HasSuffix(pattern, "}"):
+			name = strings.Trim(pattern, "{}")
+		case strings.HasPrefix(pattern, ":"):
+			name = strings.TrimPrefix(pattern, ":")
+		}
+		if name == "" {
+			if !strings.EqualFold(pattern, segments[i]) {
+				return nil, 0, false
+			}
+			staticCount++
+			continue
+		}
+		value, err := url.PathUnescape(segments[i])
+		if err != nil {
+			value = segments[i]
+		}
+		pathParams[name] = value
+	}
+	return pathParams, staticCount, true
+}
+
+// Match 按请求方法和路径查找接口,多个接口匹配时静态片段多的优先(/user/list 优先于 /user/{id})
+func (apis Apis) Match(method string, path string) (api *Api, pathParams map[string]string, err error) {
+	bestStaticCount := -1
+	for i := range apis {
+		if !strings.EqualFold(apis[i].Method, method) {
+			continue
+		}
+		params, staticCount, ok := apis[i].MatchPath(path)
+		if ok && staticCount > bestStaticCount {
+			api, pathParams, bestStaticCount = &apis[i], params, staticCount
+		}
+	}
+	if api == nil {
+		err = errors.WithMessagef(ERROR_NOT_FOUND_API, "method:%s,path:%s", method, path)
+		return nil, nil, err
+	}
+	return api, pathParams, nil
+}
+
+func splitPath(path string) (segments []string) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
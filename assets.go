@@ -0,0 +1,122 @@
+package apidocbuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 前端资源固定版本,内置到 render/assets 目录(随 HtmlTemplateFS 一起 embed),升级时同时修改下方 go:generate 地址
+//go:generate curl -sSfL -o render/assets/htmx.min.js https://unpkg.com/htmx.org@1.9.12/dist/htmx.min.js
+//go:generate curl -sSfL -o render/assets/alpine.min.js https://unpkg.com/alpinejs@3.14.1/dist/cdn.min.js
+//go:generate curl -sSfL -o render/assets/prism.min.js https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/prism.min.js
+//go:generate curl -sSfL -o render/assets/prism-json.min.js https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/components/prism-json.min.js
+//go:generate curl -sSfL -o render/assets/prism.min.css https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/themes/prism.min.css
+
+const (
+	ASSET_HTMX       = "htmx.min.js"
+	ASSET_ALPINE     = "alpine.min.js"
+	ASSET_PRISM      = "prism.min.js"
+	ASSET_PRISM_JSON = "prism-json.min.js"
+	ASSET_PRISM_CSS  = "prism.min.css"
+
+	assetDir = "render/assets"
+)
+
+var (
+	ERROR_NOT_FOUND_ASSET = errors.New("not found asset")
+)
+
+// Asset 页面依赖的前端资源
+type Asset struct {
+	Name    string
+	Version string
+	// 公共 CDN 地址,仅在显式开启 CDN 时使用
+	CDN string
+}
+
+type Assets []Asset
+
+// AssetsDefault 调试表单使用的前端资源
+var AssetsDefault = Assets{
+	{Name: ASSET_HTMX, Version: "1.9.12", CDN: "https://unpkg.com/htmx.org@1.9.12/dist/htmx.min.js"},
+	{Name: ASSET_ALPINE, Version: "3.14.1", CDN: "https://unpkg.com/alpinejs@3.14.1/dist/cdn.min.js"},
+	{Name: ASSET_PRISM, Version: "1.29.0", CDN: "https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/prism.min.js"},
+	{Name: ASSET_PRISM_JSON, Version: "1.29.0", CDN: "https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/components/prism-json.min.js"},
+	{Name: ASSET_PRISM_CSS, Version: "1.29.0", CDN: "https://cdnjs.cloudflare.com/ajax/libs/prism/1.29.0/themes/prism.min.css"},
+}
+
+func (as Assets) GetByName(name string) (asset *Asset, err error) {
+	for _, a := range as {
+		if a.Name == name {
+			return &a, nil
+		}
+	}
+	err = errors.WithMessagef(ERROR_NOT_FOUND_ASSET, "name:%s", name)
+	return nil, err
+}
+
+// Content 内置文件内容,文件缺失时返回 ERROR_NOT_FOUND_ASSET
+func (a Asset) Content() (content []byte, err error) {
+	content, err = fs.ReadFile(HtmlTemplateFS, path.Join(assetDir, a.Name))
+	if err != nil {
+		err = errors.WithMessagef(ERROR_NOT_FOUND_ASSET, "embedded name:%s", a.Name)
+		return nil, err
+	}
+	return content, nil
+}
+
+// Embedded 是否已内置
+func (a Asset) Embedded() bool {
+	_, err := fs.Stat(HtmlTemplateFS, path.Join(assetDir, a.Name))
+	return err == nil
+}
+
+func (a Asset) ContentType() string {
+	contentType := mime.TypeByExtension(path.Ext(a.Name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return contentType
+}
+
+// ETag 版本加内容摘要
+func (a Asset) ETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf(`"%s-%s"`, a.Version, hex.EncodeToString(sum[:8]))
+}
+
+// AssetLinker 生成页面中资源的地址,默认使用内置资源,内网环境无需访问外网
+type AssetLinker struct {
+	// 自定义资源地址前缀(如公司内部 CDN),设置后所有资源从 BaseURL/名称 加载
+	BaseURL string
+	// 使用 Asset.CDN 公共地址,需显式开启
+	CDN bool
+	// 内置资源的访问地址前缀(如 DocHandler 的 /assets),为空时为页面同级的 assets 目录(与静态站点结构一致)
+	local string
+}
+
+// URL 模板中使用 {{.Assets.URL "htmx.min.js"}}
+func (l AssetLinker) URL(name string) string {
+	asset, err := AssetsDefault.GetByName(name)
+	if err != nil {
+		return name
+	}
+	if l.BaseURL != "" {
+		return fmt.Sprintf("%s/%s", strings.TrimRight(l.BaseURL, "/"), asset.Name)
+	}
+	if l.CDN {
+		return asset.CDN
+	}
+	local := l.local
+	if local == "" {
+		local = STATIC_SITE_DIR_ASSETS
+	}
+	return fmt.Sprintf("%s/%s?v=%s", local, asset.Name, asset.Version)
+}
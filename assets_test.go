@@ -0,0 +1,68 @@
+package apidocbuilder_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestAssetsEmbedded(t *testing.T) {
+	for _, asset := range apidocbuilder.AssetsDefault {
+		require.True(t, asset.Embedded(), "%s 未内置,执行 go generate 后提交 render/assets", asset.Name)
+		content, err := asset.Content()
+		require.NoError(t, err)
+		require.NotEmpty(t, content, asset.Name)
+	}
+}
+
+func TestAssetLinker(t *testing.T) {
+	linker := apidocbuilder.AssetLinker{}
+	require.Equal(t, "assets/htmx.min.js?v=1.9.12", linker.URL(apidocbuilder.ASSET_HTMX))
+
+	linker.CDN = true
+	htmx, err := apidocbuilder.AssetsDefault.GetByName(apidocbuilder.ASSET_HTMX)
+	require.NoError(t, err)
+	require.Equal(t, htmx.CDN, linker.URL(apidocbuilder.ASSET_HTMX))
+
+	linker.BaseURL = "https://cdn.example.com/apidoc/"
+	require.Equal(t, "https://cdn.example.com/apidoc/htmx.min.js", linker.URL(apidocbuilder.ASSET_HTMX))
+
+	_, err = apidocbuilder.AssetsDefault.GetByName("notExists.js")
+	require.ErrorIs(t, err, apidocbuilder.ERROR_NOT_FOUND_ASSET)
+}
+
+func TestDocHandlerAssets(t *testing.T) {
+	service := newEnvironmentService()
+	handler := apidocbuilder.NewDocHandler(*service, "/docs")
+	get := func(target string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for key := range header {
+			r.Header.Set(key, header.Get(key))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	require.Equal(t, http.StatusNotFound, get("/docs/assets/notExists.js", nil).Code)
+
+	for _, asset := range apidocbuilder.AssetsDefault {
+		w := get("/docs/assets/"+asset.Name, nil)
+		form := get("/docs/form?name=getUser", nil).Body.String()
+		require.Equal(t, http.StatusOK, w.Code, asset.Name)
+		require.Equal(t, asset.ContentType(), w.Header().Get("Content-Type"))
+		require.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+		require.Contains(t, form, "/docs/assets/"+asset.Name+"?v="+asset.Version)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		require.Equal(t, http.StatusNotModified, get("/docs/assets/"+asset.Name, http.Header{"If-None-Match": []string{etag}}).Code)
+	}
+
+	handler.AssetCDN = true
+	require.Contains(t, get("/docs/form?name=getUser", nil).Body.String(), "https://unpkg.com/htmx.org@1.9.12/dist/htmx.min.js")
+
+	handler.AssetBaseURL = "https://cdn.example.com/apidoc"
+	require.Contains(t, get("/docs/form?name=getUser", nil).Body.String(), `src="https://cdn.example.com/apidoc/htmx.min.js"`)
+}
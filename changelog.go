@@ -0,0 +1,150 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Changelog 按版本倒序的接口变更记录
+type Changelog struct {
+	Service  string            `json:"service"`
+	Versions ChangelogVersions `json:"versions"`
+}
+
+type ChangelogVersions []ChangelogVersion
+
+// ChangelogVersion 相邻两个快照之间的变更,按分组归类
+type ChangelogVersion struct {
+	Version         string           `json:"version"`
+	PreviousVersion string           `json:"previousVersion"`
+	Groups          []ChangelogGroup `json:"groups"`
+}
+
+func (v ChangelogVersion) Changes() (changes ApiChanges) {
+	changes = make(ApiChanges, 0)
+	for _, group := range v.Groups {
+		for _, api := range group.Apis {
+			changes = append(changes, api.Changes...)
+		}
+	}
+	return changes
+}
+
+type ChangelogGroup struct {
+	Name string         `json:"name"`
+	Apis []ChangelogApi `json:"apis"`
+}
+
+type ChangelogApi struct {
+	Name        string     `json:"name"`
+	Title       string     `json:"title"`
+	Method      string     `json:"method"`
+	Path        string     `json:"path"`
+	DocumentRef string     `json:"documentRef"`
+	Changes     ApiChanges `json:"changes"`
+}
+
+// NewChangelog snapshots 按版本从旧到新排列,相邻快照逐个比较
+func NewChangelog(snapshots ...Service) (changelog Changelog) {
+	changelog = Changelog{Versions: make(ChangelogVersions, 0)}
+	if len(snapshots) > 0 {
+		changelog.Service = snapshots[len(snapshots)-1].Name
+	}
+	for i := len(snapshots) - 1; i > 0; i-- {
+		changelog.Versions = append(changelog.Versions, newChangelogVersion(snapshots[i-1], snapshots[i], i))
+	}
+	return changelog
+}
+
+// LoadServiceSnapshots 读取服务 json 快照(如 DocHandler 的 service.json),文件按版本从旧到新传入
+func LoadServiceSnapshots(filenames ...string) (snapshots []Service, err error) {
+	snapshots = make([]Service, 0, len(filenames))
+	for _, filename := range filenames {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "read snapshot %s", filename)
+		}
+		var service Service
+		if err = json.Unmarshal(b, &service); err != nil {
+			return nil, errors.WithMessagef(err, "unmarshal snapshot %s", filename)
+		}
+		snapshots = append(snapshots, service)
+	}
+	return snapshots, nil
+}
+
+func newChangelogVersion(old Service, new Service, index int) (version ChangelogVersion) {
+	diff := DiffService(old, new)
+	version = ChangelogVersion{
+		Version:         firstNotEmpty(new.Version, fmt.Sprintf("#%d", index+1)),
+		PreviousVersion: firstNotEmpty(old.Version, fmt.Sprintf("#%d", index)),
+		Groups:          make([]ChangelogGroup, 0),
+	}
+	groupIndex := make(map[string]int)
+	apiIndex := make(map[string][2]int)
+	for _, change := range diff.Changes {
+		api, ok := changedApi(new, old, change)
+		key := change.Api + " " + change.Method + " " + change.Path
+		position, exists := apiIndex[key]
+		if !exists {
+			i, groupExists := groupIndex[api.Group]
+			if !groupExists {
+				i = len(version.Groups)
+				groupIndex[api.Group] = i
+				version.Groups = append(version.Groups, ChangelogGroup{Name: api.Group})
+			}
+			changelogApi := ChangelogApi{Name: change.Api, Title: api.TitleOrDescription(), Method: change.Method, Path: change.Path}
+			if ok && change.Action != CHANGE_ACTION_REMOVED {
+				changelogApi.DocumentRef = api.DocumentRef
+			}
+			position = [2]int{i, len(version.Groups[i].Apis)}
+			apiIndex[key] = position
+			version.Groups[i].Apis = append(version.Groups[i].Apis, changelogApi)
+		}
+		changelogApi := &version.Groups[position[0]].Apis[position[1]]
+		changelogApi.Changes = append(changelogApi.Changes, change)
+	}
+	sort.SliceStable(version.Groups, func(i, j int) bool { return version.Groups[i].Name < version.Groups[j].Name })
+	return version
+}
+
+// changedApi 变更所属的接口,删除的接口从旧版本中查找
+func changedApi(new Service, old Service, change ApiChange) (api Api, ok bool) {
+	services := []Service{new, old}
+	if change.Action == CHANGE_ACTION_REMOVED && change.Position == CHANGE_POSITION_API {
+		services = []Service{old}
+	}
+	for _, service := range services {
+		for _, a := range service.Apis {
+			if a.IsSameName(change.Api) && a.IsSameMethodAndPath(change.Method, change.Path) {
+				return a, true
+			}
+		}
+	}
+	return Api{Name: change.Api}, false
+}
+
+// LinkApis 接口链接指向 service 的文档门户,service 中已不存在的接口不设置链接
+func (c *Changelog) LinkApis(service Service) *Changelog {
+	for i := range c.Versions {
+		for j := range c.Versions[i].Groups {
+			for k := range c.Versions[i].Groups[j].Apis {
+				api := &c.Versions[i].Groups[j].Apis[k]
+				api.DocumentRef = ""
+				if _, err := service.GetApiByName(api.Name); err == nil {
+					api.DocumentRef = fmt.Sprintf("%s?%s=%s", service.DocumentRef, DOC_QUERY_NAME, api.Name)
+				}
+			}
+		}
+	}
+	return c
+}
+
+// Markdown 按版本、分组生成变更记录
+func (c Changelog) Markdown() (out []byte, err error) {
+	return ExecTpl(TPL_NAME_MARKDOWN_CHANGELOG, c)
+}
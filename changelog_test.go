@@ -0,0 +1,80 @@
+package apidocbuilder_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestChangelog(t *testing.T) {
+	v1 := apidocbuilder.Service{Name: "user", Version: "v1", Apis: apidocbuilder.Apis{
+		{Name: "getUser", Title: "获取用户", Group: "用户", Method: "GET", Path: "/user/get", DocumentRef: "http://doc.com/user"},
+		{Name: "getOrder", Title: "获取订单", Group: "订单", Method: "GET", Path: "/order/get"},
+	}}
+	v2 := apidocbuilder.Service{Name: "user", Version: "v2", Apis: apidocbuilder.Apis{
+		{Name: "getUser", Title: "获取用户", Group: "用户", Method: "GET", Path: "/user/get", DocumentRef: "http://doc.com/user",
+			Query: apidocbuilder.Query{{Fullname: "id", Type: "int", Required: true}}},
+		{Name: "getOrder", Title: "获取订单", Group: "订单", Method: "GET", Path: "/order/get"},
+		{Name: "addUser", Title: "新增用户", Group: "用户", Method: "POST", Path: "/user/add"},
+	}}
+	v3 := v2
+	v3.Version = "v3"
+	v3.Apis = v2.Apis[:1]
+
+	dir := t.TempDir()
+	filenames := make([]string, 0)
+	for _, service := range []apidocbuilder.Service{v1, v2, v3} {
+		filename := filepath.Join(dir, service.Version+".json")
+		b, err := service.Apis.Json()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filename, []byte(`{"name":"user","version":"`+service.Version+`","apis":`+string(b)+`}`), 0o644))
+		filenames = append(filenames, filename)
+	}
+	snapshots, err := apidocbuilder.LoadServiceSnapshots(filenames...)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+
+	changelog := apidocbuilder.NewChangelog(snapshots...)
+	require.Len(t, changelog.Versions, 2)
+	require.Equal(t, "v3", changelog.Versions[0].Version)
+	require.Equal(t, "v2", changelog.Versions[0].PreviousVersion)
+	latest := changelog.Versions[0]
+	require.Equal(t, []string{"用户", "订单"}, []string{latest.Groups[0].Name, latest.Groups[1].Name})
+	require.Len(t, latest.Changes().Breaking(), 2)
+
+	md, err := changelog.Markdown()
+	require.NoError(t, err)
+	s := string(md)
+	require.Contains(t, s, "## v2")
+	require.Contains(t, s, "- [获取用户](http://doc.com/user) `GET /user/get`")
+	require.Contains(t, s, "  - **[不兼容]** query `id`: 新增必填参数")
+	require.Contains(t, s, "- 获取订单 `GET /order/get`\n  - **[不兼容]** 接口已删除")
+	require.Less(t, strings.Index(s, "## v3"), strings.Index(s, "## v2"))
+
+	handler := apidocbuilder.NewDocHandler(v3, "/docs")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/changes", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	handler.WithChangelog(changelog)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/changes.md", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "[获取用户](/docs?name=getUser)")
+	require.Contains(t, w.Body.String(), "- 新增用户 `POST /user/add`")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/changes", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `<a href="/docs?name=getUser">获取用户</a>`)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.Contains(t, w.Body.String(), `href="/docs/changes"`)
+}
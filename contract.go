@@ -0,0 +1,494 @@
+package apidocbuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CONTRACT_DIFF_KIND_MISSING = "missing" // 案例中有,实际响应中没有
+	CONTRACT_DIFF_KIND_EXTRA   = "extra"   // 实际响应中有,案例中没有
+	CONTRACT_DIFF_KIND_TYPE    = "type"
+	CONTRACT_DIFF_KIND_VALUE   = "value"
+)
+
+// ContractRunner 重放接口案例请求,校验真实响应与文档是否一致
+type ContractRunner struct {
+	service Service
+	baseURL string
+	handler http.Handler
+	Client  *http.Client
+	// 导致用例失败的差异类型,默认结构差异(缺少字段、类型不同)失败,值不同只记录
+	FailDiffKinds []string
+	// 不为空时执行服务、案例的 tengo 前置、后置、测试脚本
+	Scripts *ScriptRuntime
+	// 脚本共享变量,默认取服务变量,前一个案例脚本设置的变量后续案例可使用,请求中的 {{name}} 使用该变量替换
+	Variables map[string]string
+}
+
+// NewContractRunner 请求发送到 baseURL 对应的服务
+func NewContractRunner(service Service, baseURL string) (runner *ContractRunner) {
+	return &ContractRunner{
+		service:       service,
+		baseURL:       baseURL,
+		Client:        http.DefaultClient,
+		FailDiffKinds: []string{CONTRACT_DIFF_KIND_MISSING, CONTRACT_DIFF_KIND_TYPE},
+		Variables:     service.Variables.ToMapWithEnv(),
+	}
+}
+
+// NewContractRunnerWithHandler 请求直接由 handler 处理,不经过网络
+func NewContractRunnerWithHandler(service Service, handler http.Handler) (runner *ContractRunner) {
+	runner = NewContractRunner(service, "")
+	runner.handler = handler
+	return runner
+}
+
+type ContractReport struct {
+	Service  string           `json:"service"`
+	Results  []ContractResult `json:"results"`
+	Duration time.Duration    `json:"duration"`
+}
+
+func (report ContractReport) Count() (total int, failed int, skipped int) {
+	for _, result := range report.Results {
+		total++
+		switch {
+		case result.Skipped != "":
+			skipped++
+		case !result.Passed():
+			failed++
+		}
+	}
+	return total, failed, skipped
+}
+
+func (report ContractReport) Passed() bool {
+	_, failed, _ := report.Count()
+	return failed == 0
+}
+
+type ContractResult struct {
+	Group   string `json:"group"`
+	ApiName string `json:"apiName"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Example string `json:"example"`
+	URL     string `json:"url"`
+	// 期望状态码,案例标签为状态码时取标签,否则为 200(任意2xx均可)
+	ExpectedStatus int              `json:"expectedStatus"`
+	Status         int              `json:"status"`
+	Duration       time.Duration    `json:"duration"`
+	SchemaErrors   ValidationErrors `json:"schemaErrors,omitempty"`
+	Diffs          []ContractDiff   `json:"diffs,omitempty"`
+	// 请求失败等错误
+	Error string `json:"error,omitempty"`
+	// 跳过原因
+	Skipped string `json:"skipped,omitempty"`
+	// 导致失败的差异数量
+	FailedDiffs int `json:"failedDiffs"`
+	// 后置脚本、测试脚本断言结果
+	Assertions ScriptAssertions `json:"assertions,omitempty"`
+	Logs       []string         `json:"logs,omitempty"`
+}
+
+func (r ContractResult) Name() string {
+	name := fmt.Sprintf("%s %s", r.Method, r.Path)
+	if r.Example != "" {
+		name = fmt.Sprintf("%s [%s]", name, r.Example)
+	}
+	return name
+}
+
+func (r ContractResult) Passed() bool {
+	return r.Skipped == "" && r.Error == "" && r.statusOk() && len(r.SchemaErrors) == 0 && r.FailedDiffs == 0 && len(r.Assertions.Failed()) == 0
+}
+
+func (r ContractResult) statusOk() bool {
+	if r.ExpectedStatus == http.StatusOK {
+		return r.Status >= 200 && r.Status < 300
+	}
+	return r.Status == r.ExpectedStatus
+}
+
+// Failures 失败原因,报告中使用
+func (r ContractResult) Failures() (failures []string) {
+	if r.Error != "" {
+		failures = append(failures, r.Error)
+	}
+	if r.Error == "" && !r.statusOk() {
+		failures = append(failures, fmt.Sprintf("status: expected %d, got %d", r.ExpectedStatus, r.Status))
+	}
+	for _, e := range r.SchemaErrors {
+		failures = append(failures, fmt.Sprintf("schema %s: %s", e.Path, e.Message))
+	}
+	for _, a := range r.Assertions.Failed() {
+		failures = append(failures, strings.TrimSuffix(fmt.Sprintf("assert %s: %s", a.Name, a.Message), ": "))
+	}
+	return failures
+}
+
+// ContractDiff 案例响应与实际响应的差异
+type ContractDiff struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	// 是否导致用例失败
+	Fail bool `json:"fail"`
+}
+
+// Run 按接口、案例顺序依次请求,单个用例失败不影响后续用例
+func (runner *ContractRunner) Run(ctx context.Context) (report ContractReport, err error) {
+	start := time.Now()
+	report = ContractReport{Service: runner.service.Name, Results: make([]ContractResult, 0)}
+	for _, api := range runner.service.Apis {
+		if len(api.Examples) == 0 {
+			report.Results = append(report.Results, ContractResult{Group: api.Group, ApiName: api.Name, Method: api.Method, Path: api.Path, Skipped: "no examples"})
+			continue
+		}
+		for _, example := range api.Examples {
+			if err = ctx.Err(); err != nil {
+				return report, err
+			}
+			report.Results = append(report.Results, runner.runExample(ctx, api, *example))
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+func (runner *ContractRunner) runExample(ctx context.Context, api Api, example Example) (result ContractResult) {
+	result = ContractResult{
+		Group:          api.Group,
+		ApiName:        api.Name,
+		Method:         strings.ToUpper(api.Method),
+		Path:           api.Path,
+		Example:        firstNotEmpty(example.Title, example.Tag),
+		ExpectedStatus: mockStatus(example.Tag),
+	}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+	if runner.Variables == nil {
+		runner.Variables = make(map[string]string)
+	}
+	// 案例变量优先,其次为服务变量及脚本设置的变量
+	resolver := NewVariableResolver().AddVariables(VARIABLE_SCOPE_EXAMPLE, example.Variables).AddScope(VARIABLE_SCOPE_RUNTIME, runner.Variables)
+	resolver.LookupEnv = os.LookupEnv
+	request, err := api.SnippetRequestWithResolver(Server{URL: runner.baseURL}, example, resolver)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if runner.Scripts != nil {
+		preScripts := uniqueScripts(append(append(Scripts{}, runner.service.RequestPreScript...), example.RequestPreScript...))
+		if err = runner.runPreScripts(ctx, preScripts, &request, &result); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		request = request.Resolve(resolver) // 前置脚本设置的变量
+	}
+	result.URL = request.URL
+	status, header, body, err := runner.do(ctx, request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = status
+	if runner.Scripts != nil {
+		postScripts := uniqueScripts(append(append(Scripts{}, runner.service.RequestPostScript...), example.RequestPostScript...))
+		response := ScriptResponse{Status: status, Headers: make(map[string]string), Body: string(body)}
+		for key := range header {
+			response.Headers[key] = header.Get(key)
+		}
+		sc := &ScriptContext{Request: snippetRequest2ScriptRequest(request), Response: &response, Variables: runner.Variables}
+		scriptResult, err := runner.Scripts.RunPost(ctx, postScripts, example.TestScript, sc)
+		runner.setVariables(sc.Variables)
+		result.Assertions, result.Logs = append(result.Assertions, scriptResult.Assertions...), append(result.Logs, scriptResult.Logs...)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	isJson := api.ResponseContentType == "" || api.IsResponseContentTypeJson()
+	if status < 200 || status >= 300 || !isJson {
+		return result
+	}
+	result.SchemaErrors = api.ResponseBody.ValidateJson(body)
+	if strings.TrimSpace(example.Response) == "" {
+		return result
+	}
+	var expected, actual any
+	if err := json.Unmarshal([]byte(example.Response), &expected); err != nil {
+		return result // 案例响应不是json时不比较
+	}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		result.Error = fmt.Sprintf("response is not json: %s", err.Error())
+		return result
+	}
+	result.Diffs = diffJson(expected, actual, "")
+	for i := range result.Diffs {
+		if containsString(runner.FailDiffKinds, result.Diffs[i].Kind) {
+			result.Diffs[i].Fail = true
+			result.FailedDiffs++
+		}
+	}
+	return result
+}
+
+// runPreScripts 执行前置脚本,脚本修改后的请求写回 request
+func (runner *ContractRunner) runPreScripts(ctx context.Context, scripts Scripts, request *SnippetRequest, result *ContractResult) (err error) {
+	sc := &ScriptContext{Request: snippetRequest2ScriptRequest(*request), Variables: runner.Variables}
+	scriptResult, err := runner.Scripts.RunPre(ctx, scripts, sc)
+	runner.setVariables(sc.Variables)
+	result.Assertions, result.Logs = append(result.Assertions, scriptResult.Assertions...), append(result.Logs, scriptResult.Logs...)
+	if err != nil {
+		return err
+	}
+	request.Method, request.URL, request.Body = strings.ToUpper(sc.Request.Method), sc.Request.URL, sc.Request.Body
+	request.Headers = make([]SnippetPair, 0, len(sc.Request.Headers))
+	for _, key := range sortedKeys(sc.Request.Headers) {
+		if strings.EqualFold(key, HEADER_NAME_CONTENT_TYPE) {
+			request.ContentType = sc.Request.Headers[key]
+			continue
+		}
+		request.Headers = append(request.Headers, SnippetPair{Key: key, Value: sc.Request.Headers[key]})
+	}
+	return nil
+}
+
+// setVariables 原地更新,变量解析器引用同一个 map
+func (runner *ContractRunner) setVariables(variables map[string]string) {
+	for key := range runner.Variables {
+		if _, ok := variables[key]; !ok {
+			delete(runner.Variables, key)
+		}
+	}
+	for key, value := range variables {
+		runner.Variables[key] = value
+	}
+}
+
+func snippetRequest2ScriptRequest(request SnippetRequest) (scriptRequest ScriptRequest) {
+	scriptRequest = ScriptRequest{Method: request.Method, URL: request.URL, Body: request.Body, Headers: make(map[string]string)}
+	for _, h := range request.Headers {
+		scriptRequest.Headers[h.Key] = h.Value
+	}
+	if request.ContentType != "" {
+		scriptRequest.Headers[HEADER_NAME_CONTENT_TYPE] = request.ContentType
+	}
+	return scriptRequest
+}
+
+func (runner *ContractRunner) do(ctx context.Context, request SnippetRequest) (status int, header http.Header, body []byte, err error) {
+	var reader io.Reader = strings.NewReader(request.Body)
+	contentType := ""
+	if len(request.FormData) > 0 {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, f := range request.FormData {
+			if err = writer.WriteField(f.Key, f.Value); err != nil {
+				return 0, nil, nil, err
+			}
+		}
+		if err = writer.Close(); err != nil {
+			return 0, nil, nil, err
+		}
+		reader, contentType = &buf, writer.FormDataContentType()
+	}
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, reader)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	for _, h := range request.Headers {
+		req.Header.Set(h.Key, h.Value)
+	}
+	if contentType == "" && request.Body != "" {
+		contentType = request.ContentType
+	}
+	if contentType != "" {
+		req.Header.Set(HEADER_NAME_CONTENT_TYPE, contentType)
+	}
+
+	if runner.handler != nil {
+		w := httptest.NewRecorder()
+		runner.handler.ServeHTTP(w, req)
+		return w.Code, w.Header(), w.Body.Bytes(), nil
+	}
+	resp, err := runner.Client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+// diffJson 递归比较json值,对象按键比较,数组按下标比较
+func diffJson(expected any, actual any, path string) (diffs []ContractDiff) {
+	expectedType, actualType := jsonTypeName(expected), jsonTypeName(actual)
+	if expectedType != actualType {
+		if expected != nil && actual != nil { // null 与具体值视为值不同
+			return []ContractDiff{{Path: path, Kind: CONTRACT_DIFF_KIND_TYPE, Expected: expectedType, Actual: actualType}}
+		}
+		return []ContractDiff{{Path: path, Kind: CONTRACT_DIFF_KIND_VALUE, Expected: jsonString(expected), Actual: jsonString(actual)}}
+	}
+	switch e := expected.(type) {
+	case map[string]any:
+		a := actual.(map[string]any)
+		for _, key := range sortedKeys(e) {
+			childPath := joinJsonPath(path, key)
+			if _, ok := a[key]; !ok {
+				diffs = append(diffs, ContractDiff{Path: childPath, Kind: CONTRACT_DIFF_KIND_MISSING, Expected: jsonString(e[key])})
+				continue
+			}
+			diffs = append(diffs, diffJson(e[key], a[key], childPath)...)
+		}
+		for _, key := range sortedKeys(a) {
+			if _, ok := e[key]; !ok {
+				diffs = append(diffs, ContractDiff{Path: joinJsonPath(path, key), Kind: CONTRACT_DIFF_KIND_EXTRA, Actual: jsonString(a[key])})
+			}
+		}
+	case []any:
+		a := actual.([]any)
+		for i := 0; i < len(e) || i < len(a); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				diffs = append(diffs, ContractDiff{Path: childPath, Kind: CONTRACT_DIFF_KIND_VALUE, Expected: jsonString(e[i])})
+			case i >= len(e):
+				diffs = append(diffs, ContractDiff{Path: childPath, Kind: CONTRACT_DIFF_KIND_VALUE, Actual: jsonString(a[i])})
+			default:
+				diffs = append(diffs, diffJson(e[i], a[i], childPath)...)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			diffs = append(diffs, ContractDiff{Path: path, Kind: CONTRACT_DIFF_KIND_VALUE, Expected: jsonString(expected), Actual: jsonString(actual)})
+		}
+	}
+	return diffs
+}
+
+func joinJsonPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return OpenAPI_Type_Null
+	case bool:
+		return OpenAPI_Type_Boolean
+	case float64:
+		return OpenAPI_Type_Number
+	case string:
+		return OpenAPI_Type_String
+	case []any:
+		return OpenAPI_Type_Array
+	case map[string]any:
+		return OpenAPI_Type_Object
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// JUnit xml 格式,CI 系统可直接展示
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitXML 生成 JUnit xml 报告,每个案例为一个 testcase
+func (report ContractReport) JUnitXML() (out []byte, err error) {
+	total, failed, skipped := report.Count()
+	seconds := fmt.Sprintf("%.3f", report.Duration.Seconds())
+	suite := junitTestSuite{Name: report.Service, Tests: total, Failures: failed, Skipped: skipped, Time: seconds}
+	for _, result := range report.Results {
+		testCase := junitTestCase{
+			ClassName: strings.Trim(fmt.Sprintf("%s.%s", result.Group, result.ApiName), "."),
+			Name:      result.Name(),
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+		switch {
+		case result.Skipped != "":
+			testCase.Skipped = &junitMessage{Message: result.Skipped}
+		case !result.Passed():
+			failures := result.Failures()
+			for _, diff := range result.Diffs {
+				if diff.Fail {
+					failures = append(failures, fmt.Sprintf("diff %s %s: expected %s, got %s", diff.Kind, diff.Path, diff.Expected, diff.Actual))
+				}
+			}
+			testCase.Failure = &junitMessage{Message: failures[0], Text: strings.Join(failures, "\n")}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suites := junitTestSuites{Tests: total, Failures: failed, Skipped: skipped, Time: seconds, Suites: []junitTestSuite{suite}}
+	b, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		err = errors.WithMessage(err, "marshal junit xml")
+		return nil, err
+	}
+	out = append([]byte(xml.Header), b...)
+	return out, nil
+}
+
+// Markdown 生成 markdown 报告
+func (report ContractReport) Markdown() (out []byte, err error) {
+	return ExecTpl(TPL_NAME_MARKDOWN_CONTRACT, report)
+}
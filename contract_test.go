@@ -0,0 +1,78 @@
+package apidocbuilder_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestContractRunner(t *testing.T) {
+	contractService := apidocbuilder.Service{
+		Name: "user",
+		Apis: apidocbuilder.Apis{
+			{
+				Name: "getUser", Method: "GET", Path: "/user/{id}",
+				ResponseBody: apidocbuilder.Parameters{
+					{Fullname: "id", Type: "int", Required: true},
+					{Fullname: "status", Type: "string", Enum: "on,off"},
+				},
+				Examples: apidocbuilder.Examples{
+					{Title: "正常", URL: "/user/1", Response: `{"id":1,"status":"on","name":"tom"}`},
+					{Title: "不存在", Tag: "404", URL: "/user/2"},
+				},
+			},
+			{Name: "deleteUser", Method: "DELETE", Path: "/user/{id}"},
+		},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","status":"deleted","name":"jerry"}`))
+	})
+	report, err := apidocbuilder.NewContractRunnerWithHandler(contractService, handler).Run(context.Background())
+	require.NoError(t, err)
+	total, failed, skipped := report.Count()
+	require.Equal(t, []int{3, 1, 1}, []int{total, failed, skipped})
+
+	result := report.Results[0]
+	require.False(t, result.Passed())
+	require.Len(t, result.SchemaErrors, 2)
+	require.Equal(t, "id", result.SchemaErrors[0].Path)
+	require.Equal(t, apidocbuilder.VALIDATION_RULE_ENUM, result.SchemaErrors[1].Rule)
+	require.Equal(t, 1, result.FailedDiffs) // id 类型不同失败,name 值不同只记录
+	require.Len(t, result.Diffs, 3)
+	require.True(t, report.Results[1].Passed())
+
+	junit, err := report.JUnitXML()
+	require.NoError(t, err)
+	require.Contains(t, string(junit), `<testsuites tests="3" failures="1" skipped="1"`)
+	require.Contains(t, string(junit), `<skipped message="no examples">`)
+
+	md, err := report.Markdown()
+	require.NoError(t, err)
+	require.Contains(t, string(md), "|**失败**|GET /user/{id}|正常|200|")
+	require.Contains(t, string(md), "|id|type|number|string|true|")
+}
+
+func TestContractReportMarkdownEscape(t *testing.T) {
+	report := apidocbuilder.ContractReport{
+		Service: "user",
+		Results: []apidocbuilder.ContractResult{{
+			Method: "GET", Path: "/user", Example: "a|b", Status: 200, FailedDiffs: 1,
+			Diffs:      []apidocbuilder.ContractDiff{{Path: "remark", Kind: apidocbuilder.CONTRACT_DIFF_KIND_VALUE, Expected: `"x|y"`, Actual: "\"line1\nline2\""}},
+			Assertions: apidocbuilder.ScriptAssertions{{Name: "status|code", Message: "expected 200\r\ngot 500"}},
+		}},
+	}
+	md, err := report.Markdown()
+	require.NoError(t, err)
+	s := string(md)
+	require.Contains(t, s, `|GET /user|a\|b|200|`)
+	require.Contains(t, s, "|status\\|code|**失败**|expected 200<br>got 500|")
+	require.Contains(t, s, "|remark|value|\"x\\|y\"|\"line1<br>line2\"|")
+}
@@ -0,0 +1,251 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ERROR_DEBUG_PROXY_HOST_NOT_ALLOWED = errors.New("debug proxy host not allowed")
+)
+
+// DebugProxyResponse 调试代理返回给表单的响应
+type DebugProxyResponse struct {
+	URL     string            `json:"url"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	// 耗时(毫秒)
+	Duration int64  `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DebugProxy 调试表单的服务端代理:浏览器请求本服务,由本服务转发到所选环境的服务器(经过服务器配置的http代理),避免跨域
+type DebugProxy struct {
+	service Service
+	// 允许转发的主机,支持 *.example.com 通配;默认为服务所有环境的主机,防止被用于 SSRF
+	AllowHosts []string
+	Timeout    time.Duration
+	// 响应体最大字节数
+	MaxBodySize int64
+	// 透传调试者请求中的 Authorization、Cookie;默认关闭,避免文档站点的会话信息发送到其它服务器
+	ForwardCredentials bool
+}
+
+func NewDebugProxy(service Service) (proxy *DebugProxy) {
+	proxy = &DebugProxy{service: service, Timeout: 30 * time.Second, MaxBodySize: 10 << 20}
+	for _, server := range service.Servers {
+		resolver := NewVariableResolver().AddVariables(VARIABLE_SCOPE_SERVER, server.Variables).AddVariables(VARIABLE_SCOPE_SERVICE, service.Variables)
+		resolver.LookupEnv = os.LookupEnv
+		u, err := url.Parse(resolver.Resolve(server.URL))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if !containsString(proxy.AllowHosts, u.Host) {
+			proxy.AllowHosts = append(proxy.AllowHosts, u.Host)
+		}
+	}
+	return proxy
+}
+
+// ServeHTTP ?name=接口名&env=环境,请求体为表单提交的json,GET/HEAD 接口转换为query参数
+func (p *DebugProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	api, err := p.service.GetApiByName(query.Get(DOC_QUERY_NAME))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	apiCopy := *api
+	apiCopy.Service = &p.service
+	body, err := io.ReadAll(io.LimitReader(r.Body, p.MaxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response, err := p.Do(r, apiCopy, query.Get(DOC_QUERY_ENVIRONMENT), body)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, ERROR_DEBUG_PROXY_HOST_NOT_ALLOWED):
+			status = http.StatusForbidden
+		case errors.Is(err, ERROR_NOT_FOUND_SERVER):
+			status = http.StatusNotFound
+		}
+		response.Error = err.Error()
+		writeDebugProxyResponse(w, status, response)
+		return
+	}
+	writeDebugProxyResponse(w, http.StatusOK, response)
+}
+
+// Do 构造请求并转发到指定环境,environment 为空时使用默认环境
+func (p *DebugProxy) Do(r *http.Request, api Api, environment string, body []byte) (response DebugProxyResponse, err error) {
+	server, err := api.GetServer(environment)
+	if err != nil {
+		return response, err
+	}
+	example := Example{ContentType: r.Header.Get(HEADER_NAME_CONTENT_TYPE), RequestBody: string(body)}
+	method := strings.ToUpper(api.Method)
+	if method == http.MethodGet || method == http.MethodHead {
+		example.RequestBody = ""
+	}
+	// 服务器地址、代理在服务端配置,可读取环境变量;表单提交的内容不读取环境变量,防止泄露服务端密钥
+	serverResolver := api.VariableResolver(server, Example{})
+	serverResolver.LookupEnv = os.LookupEnv
+	server.URL, server.Proxy = serverResolver.Resolve(server.URL), serverResolver.Resolve(server.Proxy)
+	resolver := api.VariableResolver(server, example)
+	request, err := api.SnippetRequestWithResolver(server, example, resolver)
+	if err != nil {
+		return response, err
+	}
+	if example.RequestBody == "" && len(body) > 0 {
+		request.URL, err = mergeJsonQuery(request.URL, body)
+		if err != nil {
+			return response, err
+		}
+	}
+	response.URL = request.URL
+	target, err := url.Parse(request.URL)
+	if err != nil {
+		return response, err
+	}
+	if err = p.checkHost(target.Host); err != nil {
+		return response, err
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), request.Method, request.URL, strings.NewReader(request.Body))
+	if err != nil {
+		return response, err
+	}
+	for _, h := range request.Headers {
+		req.Header.Set(h.Key, h.Value)
+	}
+	if p.ForwardCredentials {
+		for _, name := range []string{"Authorization", "Cookie"} { // 透传调试者的鉴权信息
+			if value := r.Header.Get(name); value != "" {
+				req.Header.Set(name, value)
+			}
+		}
+	}
+	client, err := p.client(request.Proxy)
+	if err != nil {
+		return response, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	response.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, p.MaxBodySize))
+	if err != nil {
+		return response, err
+	}
+	response.Status, response.Body = resp.StatusCode, string(b)
+	response.Headers = make(map[string]string, len(resp.Header))
+	for key := range resp.Header {
+		response.Headers[key] = resp.Header.Get(key)
+	}
+	return response, nil
+}
+
+// checkHost 主机需在白名单中,重定向同样检查
+func (p *DebugProxy) checkHost(host string) (err error) {
+	hostname := host
+	if h, _, splitErr := net.SplitHostPort(host); splitErr == nil {
+		hostname = h
+	}
+	for _, allow := range p.AllowHosts {
+		if strings.EqualFold(allow, host) || strings.EqualFold(allow, hostname) {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allow, "*."); ok && strings.HasSuffix(strings.ToLower(hostname), "."+strings.ToLower(suffix)) {
+			return nil
+		}
+	}
+	return errors.WithMessagef(ERROR_DEBUG_PROXY_HOST_NOT_ALLOWED, "host:%s", host)
+}
+
+func (p *DebugProxy) client(proxy string) (client *http.Client, err error) {
+	transport := &http.Transport{Proxy: nil} // 不使用环境变量中的代理,只使用服务器配置的代理
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			err = errors.WithMessagef(err, "parse proxy:%s", proxy)
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	client = &http.Client{
+		Transport: transport,
+		Timeout:   p.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return p.checkHost(req.URL.Host)
+		},
+	}
+	return client, nil
+}
+
+// mergeJsonQuery 表单json中的字段追加到query参数
+func mergeJsonQuery(rawURL string, body []byte) (merged string, err error) {
+	data := make(map[string]any)
+	if err = json.Unmarshal(body, &data); err != nil {
+		err = errors.WithMessage(err, "form body is not json object")
+		return "", err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	values := u.Query()
+	for _, key := range sortedKeys(data) {
+		switch v := data[key].(type) {
+		case []any:
+			values.Del(key)
+			for _, item := range v {
+				values.Add(key, fmt.Sprint(item))
+			}
+		case string:
+			values.Set(key, v)
+		default:
+			values.Set(key, jsonString(v))
+		}
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+func writeDebugProxyResponse(w http.ResponseWriter, status int, response DebugProxyResponse) {
+	b, _ := json.Marshal(response)
+	w.Header().Set(HEADER_NAME_CONTENT_TYPE, Header_Value_Content_Type_Json)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// GetProxyPath 调试代理地址
+func (s *Service) GetProxyPath() (proxyUrl string) {
+	return getProxyPath(s.DocumentRef)
+}
+
+func getProxyPath(documentRef string) (proxyUrl string) {
+	return fmt.Sprintf("%s%s", documentRef, DOC_PATH_PROXY)
+}
@@ -0,0 +1,103 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestDebugProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "1")
+		out, _ := json.Marshal(map[string]string{"method": r.Method, "query": r.URL.RawQuery, "body": string(body), "auth": r.Header.Get("Authorization")})
+		w.Write(out)
+	}))
+	defer upstream.Close()
+	proxied := false
+	httpProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		upstream.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer httpProxy.Close()
+
+	t.Setenv("ZZ_DEBUG_PROXY_SECRET", "s3cret")
+	t.Setenv("ZZ_DEBUG_PROXY_UPSTREAM", upstream.URL)
+	service := apidocbuilder.Service{Name: "user"}
+	service.AddServer(
+		apidocbuilder.Server{Name: "dev", URL: upstream.URL},
+		apidocbuilder.Server{Name: "env", URL: "{{upstream}}", Variables: apidocbuilder.Variables{{Name: "upstream", Env: "ZZ_DEBUG_PROXY_UPSTREAM"}}},
+		apidocbuilder.Server{Name: "proxy", URL: upstream.URL, Proxy: httpProxy.URL},
+		apidocbuilder.Server{Name: "internal", URL: "http://{{host}}", Variables: apidocbuilder.Variables{{Name: "host", Value: "10.0.0.1"}}},
+	)
+	service.AddApi(
+		apidocbuilder.Api{Name: "addUser", Method: "POST", Path: "/user/add"},
+		apidocbuilder.Api{Name: "listUser", Method: "GET", Path: "/user/list"},
+		apidocbuilder.Api{Name: "redirect", Method: "GET", Path: "/redirect"},
+	)
+	handler := apidocbuilder.NewDocHandler(service, "/docs")
+	require.Contains(t, handler.Proxy.AllowHosts, "10.0.0.1")
+	handler.Proxy.AllowHosts = handler.Proxy.AllowHosts[:1]
+
+	post := func(target string, body string) (status int, response apidocbuilder.DebugProxyResponse) {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer abc")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	status, response := post("/docs/proxy?name=addUser&env=dev", `{"name":"tom"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, http.StatusOK, response.Status)
+	require.Equal(t, "1", response.Headers["X-Upstream"])
+	require.JSONEq(t, `{"method":"POST","query":"","body":"{\"name\":\"tom\"}","auth":""}`, response.Body)
+
+	// 表单内容中的环境变量不替换,服务器地址中的环境变量在服务端替换
+	status, response = post("/docs/proxy?name=addUser&env=env", `{"x":"{{$env.ZZ_DEBUG_PROXY_SECRET}}"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, upstream.URL+"/user/add", response.URL)
+	require.NotContains(t, response.Body, "s3cret")
+	require.Contains(t, response.Body, "ZZ_DEBUG_PROXY_SECRET")
+
+	handler.Proxy.ForwardCredentials = true
+	_, response = post("/docs/proxy?name=addUser&env=dev", `{}`)
+	require.Contains(t, response.Body, `"auth":"Bearer abc"`)
+	handler.Proxy.ForwardCredentials = false
+
+	status, response = post("/docs/proxy?name=listUser", `{"page":1,"ids":[1,2]}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, upstream.URL+"/user/list?ids=1&ids=2&page=1", response.URL)
+
+	status, _ = post("/docs/proxy?name=addUser&env=proxy", `{}`)
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, proxied)
+
+	status, response = post("/docs/proxy?name=addUser&env=internal", `{}`)
+	require.Equal(t, http.StatusForbidden, status)
+	require.Contains(t, response.Error, "not allowed")
+
+	status, response = post("/docs/proxy?name=redirect&env=dev", `{}`)
+	require.Equal(t, http.StatusForbidden, status)
+	require.Contains(t, response.Error, "169.254.169.254")
+
+	status, _ = post("/docs/proxy?name=addUser&env=staging", `{}`)
+	require.Equal(t, http.StatusNotFound, status)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/proxy?name=addUser", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
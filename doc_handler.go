@@ -0,0 +1,226 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DOC_PATH_PORTAL       = "/"
+	DOC_PATH_FORM         = "/form" // 与 getFormPath 一致
+	DOC_PATH_MARKDOWN     = "/markdown"
+	DOC_PATH_SERVICE_JSON = "/service.json"
+	DOC_PATH_OPENAPI_JSON = "/openapi.json"
+	DOC_PATH_OPENAPI_YAML = "/openapi.yaml"
+	DOC_PATH_POSTMAN_JSON = "/postman.json"
+	DOC_PATH_PROXY        = "/proxy"  // 调试表单提交到该地址,由服务端转发
+	DOC_PATH_ASSETS       = "/assets" // 内置前端资源
+	DOC_PATH_CHANGES      = "/changes"
+	DOC_PATH_CHANGES_MD   = "/changes.md"
+
+	DOC_QUERY_NAME        = "name"
+	DOC_QUERY_ENVIRONMENT = "env"
+	DOC_QUERY_SEARCH      = "q"
+)
+
+const (
+	Header_Value_Content_Type_Html     = "text/html; charset=utf-8"
+	Header_Value_Content_Type_Markdown = "text/markdown; charset=utf-8"
+	Header_Value_Content_Type_Yaml     = "application/yaml; charset=utf-8"
+)
+
+// DocHandler 提供服务文档的 http 服务:门户页(?name=接口名)、调试表单及代理、markdown、服务json 及 openapi/postman 导出
+type DocHandler struct {
+	service Service
+	prefix  string
+	// 调试代理,可修改 AllowHosts 等配置
+	Proxy *DebugProxy
+	// 自定义前端资源地址前缀,为空时使用 DOC_PATH_ASSETS 下的内置资源
+	AssetBaseURL string
+	// 前端资源使用公共 CDN 地址,需显式开启
+	AssetCDN  bool
+	changelog *Changelog
+}
+
+// NewDocHandler prefix 为挂载路径(如 /docs),服务及接口的 DocumentRef 设置为该路径,页面中的链接基于 DocumentRef 生成
+func NewDocHandler(service Service, prefix string) (handler *DocHandler) {
+	prefix = strings.TrimRight(prefix, "/")
+	apis := make(Apis, len(service.Apis))
+	copy(apis, service.Apis)
+	service.Apis = apis
+	service.DocumentRef = prefix
+	handler = &DocHandler{service: service, prefix: prefix, Proxy: NewDebugProxy(service)}
+	for i := range handler.service.Apis {
+		handler.service.Apis[i].DocumentRef = prefix
+		handler.service.Apis[i].Service = &handler.service
+	}
+	return handler
+}
+
+// WithDocumentRefDomain 文档通过网关等对外暴露时,链接使用完整域名
+func (h *DocHandler) WithDocumentRefDomain(domain string) *DocHandler {
+	h.service.WithDocumentRefDomain(domain)
+	return h
+}
+
+// WithChangelog 门户增加变更记录页面,接口链接指向本文档
+func (h *DocHandler) WithChangelog(changelog Changelog) *DocHandler {
+	changelog.LinkApis(h.service)
+	h.changelog = &changelog
+	return h
+}
+
+func (h *DocHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := h.trimPrefix(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if path == DOC_PATH_PROXY {
+		h.Proxy.ServeHTTP(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if assetName, ok := strings.CutPrefix(path, DOC_PATH_ASSETS+"/"); ok {
+		h.asset(w, r, assetName)
+		return
+	}
+	name := r.URL.Query().Get(DOC_QUERY_NAME)
+	var (
+		out         []byte
+		err         error
+		contentType = Header_Value_Content_Type_Html
+	)
+	switch path {
+	case DOC_PATH_PORTAL:
+		out, err = RenderService(ServiceRender{Service: h.service, SearchQuery: r.URL.Query().Get(DOC_QUERY_SEARCH), ChangelogRef: h.changelogRef()}, name)
+	case DOC_PATH_FORM:
+		render := ServiceRender{Service: h.service, Environment: r.URL.Query().Get(DOC_QUERY_ENVIRONMENT), AssetBaseURL: h.AssetBaseURL, AssetCDN: h.AssetCDN}
+		render.localAssetBaseURL = h.service.DocumentRef + DOC_PATH_ASSETS
+		out, err = RenderForm(render, name)
+	case DOC_PATH_CHANGES, DOC_PATH_CHANGES_MD:
+		if h.changelog == nil {
+			http.NotFound(w, r)
+			return
+		}
+		out, err = h.changelog.Markdown()
+		if path == DOC_PATH_CHANGES_MD {
+			contentType = Header_Value_Content_Type_Markdown
+		} else if err == nil {
+			out, err = Markdown2HTML(out)
+		}
+	case DOC_PATH_MARKDOWN:
+		contentType = Header_Value_Content_Type_Markdown
+		out, err = h.markdown(name)
+	case DOC_PATH_SERVICE_JSON:
+		contentType = Header_Value_Content_Type_Json
+		out, err = serviceJson(h.service)
+	case DOC_PATH_OPENAPI_JSON:
+		contentType = Header_Value_Content_Type_Json
+		out, err = Service2OpenAPIJson(h.service)
+	case DOC_PATH_OPENAPI_YAML:
+		contentType = Header_Value_Content_Type_Yaml
+		out, err = Service2OpenAPIYaml(h.service)
+	case DOC_PATH_POSTMAN_JSON:
+		contentType = Header_Value_Content_Type_Json
+		out, err = Service2PostmanJson(h.service)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ERROR_NOT_FOUND_API) || errors.Is(err, ERROR_NOT_FOUND_SERVER) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set(HEADER_NAME_CONTENT_TYPE, contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(out)
+	}
+}
+
+// asset 内置资源带版本号访问,长期缓存
+func (h *DocHandler) asset(w http.ResponseWriter, r *http.Request, name string) {
+	asset, err := AssetsDefault.GetByName(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := asset.Content()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	etag := asset.ETag(content)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set(HEADER_NAME_CONTENT_TYPE, asset.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(content)
+	}
+}
+
+func (h *DocHandler) changelogRef() string {
+	if h.changelog == nil {
+		return ""
+	}
+	return h.service.DocumentRef + DOC_PATH_CHANGES
+}
+
+// trimPrefix 去掉挂载路径,返回文档内的路径
+func (h *DocHandler) trimPrefix(urlPath string) (path string, ok bool) {
+	if h.prefix != "" {
+		if urlPath != h.prefix && !strings.HasPrefix(urlPath, h.prefix+"/") {
+			return "", false
+		}
+		urlPath = strings.TrimPrefix(urlPath, h.prefix)
+	}
+	if urlPath == "" {
+		urlPath = DOC_PATH_PORTAL
+	}
+	return urlPath, true
+}
+
+// markdown 有接口名称时返回单个接口文档,否则返回服务文档
+func (h *DocHandler) markdown(name string) (out []byte, err error) {
+	if name == "" {
+		return Service2Markdown(h.service)
+	}
+	api, err := h.service.GetApiByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return Api2Markdown(*api)
+}
+
+// serviceJson 接口中的 Service 指针指向服务本身,序列化前去掉,避免循环引用
+func serviceJson(service Service) (out []byte, err error) {
+	apis := make(Apis, len(service.Apis))
+	for i, api := range service.Apis {
+		api.Service = nil
+		apis[i] = api
+	}
+	service.Apis = apis
+	out, err = json.Marshal(service)
+	if err != nil {
+		err = errors.WithMessage(err, "marshal service json")
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestDocHandler(t *testing.T) {
+	service := newEnvironmentService()
+	service.AddApi(apidocbuilder.Api{Name: "addUser", Title: "新增用户", Method: "POST", Path: "/user/add", RequestBody: apidocbuilder.Parameters{{Fullname: "name", Type: "string", Required: true}}})
+	mux := http.NewServeMux()
+	mux.Handle("/docs/", apidocbuilder.NewDocHandler(*service, "/docs/"))
+	mux.Handle("/docs", apidocbuilder.NewDocHandler(*service, "/docs"))
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get("/docs?name=addUser")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, w.Body.String(), `href="/docs?name=getUser"`)
+	require.Contains(t, w.Body.String(), "/docs/form?name=addUser")
+
+	w = get("/docs/form?name=addUser&env=test")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `hx-post="/docs/proxy?env=test&amp;name=addUser"`)
+
+	w = get("/docs/markdown?name=addUser")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "***路径:*** /user/add")
+
+	w = get("/docs/markdown")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "## 接口列表")
+
+	w = get("/docs/service.json")
+	require.Equal(t, http.StatusOK, w.Code)
+	var decoded apidocbuilder.Service
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	require.Len(t, decoded.Apis, 2)
+
+	for _, target := range []string{"/docs/openapi.json", "/docs/openapi.yaml", "/docs/postman.json"} {
+		w = get(target)
+		require.Equal(t, http.StatusOK, w.Code, target)
+		require.NotEmpty(t, w.Body.String(), target)
+	}
+	require.True(t, strings.HasPrefix(get("/docs/openapi.yaml").Body.String(), "openapi:"))
+
+	require.Equal(t, http.StatusNotFound, get("/docs?name=notExists").Code)
+	require.Equal(t, http.StatusNotFound, get("/docs/form?name=addUser&env=staging").Code)
+	require.Equal(t, http.StatusNotFound, get("/docs/unknown").Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/docs", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	handler := apidocbuilder.NewDocHandler(*service, "/docs").WithDocumentRefDomain("https://doc.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/markdown?name=addUser", nil))
+	require.Contains(t, w.Body.String(), "https://doc.example.com/docs/form?name=addUser")
+}
@@ -0,0 +1,158 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 案例问题类型,其余与 VALIDATION_RULE_* 一致(required、type、enum 等)
+const (
+	EXAMPLE_ISSUE_UNDECLARED = "undeclared" // 案例中有、参数未定义
+)
+
+// ExampleIssue 案例与参数定义不一致之处
+type ExampleIssue struct {
+	Api     string `json:"api"`
+	Example string `json:"example"`
+	// requestBody 或 responseBody
+	Position string `json:"position"`
+	Fullname string `json:"fullname"`
+	// 案例中的实际位置,数组带下标
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+type ExampleIssues []ExampleIssue
+
+func (issues ExampleIssues) Error() string {
+	messages := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		messages = append(messages, fmt.Sprintf("%s %s %s %s: %s", issue.Api, issue.Example, issue.Position, issue.Path, issue.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ExampleChecker 检查案例请求体、响应体与 RequestBody、ResponseBody 定义是否一致
+type ExampleChecker struct {
+	// 将案例中未定义的字段按推断的类型补充到参数中
+	Complement bool
+}
+
+func NewExampleChecker() (checker *ExampleChecker) {
+	return &ExampleChecker{}
+}
+
+// CheckService 检查所有接口,Complement 时直接修改 service 中的接口参数
+func (c *ExampleChecker) CheckService(service *Service) (issues ExampleIssues) {
+	issues = make(ExampleIssues, 0)
+	for i := range service.Apis {
+		issues = append(issues, c.Check(&service.Apis[i])...)
+	}
+	return issues
+}
+
+// Check 检查接口的所有案例,非json格式的案例忽略
+func (c *ExampleChecker) Check(api *Api) (issues ExampleIssues) {
+	issues = make(ExampleIssues, 0)
+	for _, example := range api.Examples {
+		if example == nil {
+			continue
+		}
+		name := firstNotEmpty(example.Title, example.Tag)
+		var complements Parameters
+		issues, complements = c.check(issues, *api, name, CHANGE_POSITION_REQUEST_BODY, api.RequestBody, example.RequestBody)
+		if c.Complement && len(complements) > 0 {
+			api.RequestBody = api.RequestBody.MergeInferred(complements...)
+		}
+		issues, complements = c.check(issues, *api, name, CHANGE_POSITION_RESPONSE_BODY, api.ResponseBody, example.Response)
+		if c.Complement && len(complements) > 0 {
+			api.ResponseBody = api.ResponseBody.MergeInferred(complements...)
+		}
+	}
+	return issues
+}
+
+func (c *ExampleChecker) check(issues ExampleIssues, api Api, example string, position string, ps Parameters, body string) (ExampleIssues, Parameters) {
+	body = strings.TrimSpace(body)
+	if body == "" || (body[0] != '{' && body[0] != '[') {
+		return issues, nil
+	}
+	add := func(fullname string, path string, kind string, message string) {
+		issues = append(issues, ExampleIssue{Api: api.Name, Example: example, Position: position, Fullname: fullname, Path: path, Kind: kind, Message: message})
+	}
+	for _, e := range ps.ValidateJson([]byte(body)) {
+		add(e.Fullname, e.Path, e.Rule, e.Message)
+	}
+	var data any
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return issues, nil // ValidateJson 已报告
+	}
+	undeclared := undeclaredParameters(ps, Value2Parameters(data, ""))
+	for i := range undeclared {
+		p := &undeclared[i]
+		add(p.Fullname, p.Fullname, EXAMPLE_ISSUE_UNDECLARED, fmt.Sprintf("undeclared field, inferred type %s", p.Type))
+		if p.Type == OpenAPI_Type_Null {
+			p.Type = "any" // 案例值为 null 无法推断类型
+		}
+		p.FormatField()
+	}
+	return issues, undeclared
+}
+
+// undeclaredParameters 推断出的参数中未定义的部分;已定义的参数没有下级定义时视为任意结构,不再检查其下级
+func undeclaredParameters(declared Parameters, inferred Parameters) (undeclared Parameters) {
+	declaredNames := make(map[string]bool)
+	for _, p := range declared {
+		declaredNames[canonicalFullname(p.Fullname)] = true
+	}
+	opaque := func(fullname string) bool {
+		prefix := fullname + "."
+		for name := range declaredNames {
+			if strings.HasPrefix(name, prefix) || strings.HasPrefix(name, fullname+"[]") {
+				return false
+			}
+		}
+		return true
+	}
+	undeclared = make(Parameters, 0)
+	for _, p := range inferred {
+		fullname := canonicalFullname(p.Fullname)
+		if declaredNames[fullname] {
+			continue
+		}
+		covered := false
+		for _, ancestor := range fullnameAncestors(fullname) {
+			if declaredNames[ancestor] && opaque(ancestor) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			undeclared = append(undeclared, p)
+		}
+	}
+	return undeclared
+}
+
+// canonicalFullname data.[].id、data[].id 统一为 data[].id
+func canonicalFullname(fullname string) string {
+	return strings.ReplaceAll(fullname, ".[]", "[]")
+}
+
+// fullnameAncestors data.items[].id -> data、data.items、data.items[]
+func fullnameAncestors(fullname string) (ancestors []string) {
+	for i := 1; i < len(fullname); i++ {
+		switch {
+		case fullname[i] == '.':
+			ancestors = append(ancestors, fullname[:i])
+		case fullname[i] == '[' && i+1 < len(fullname) && fullname[i+1] == ']':
+			ancestors = append(ancestors, fullname[:i])
+			if i+2 < len(fullname) {
+				ancestors = append(ancestors, fullname[:i+2])
+			}
+		}
+	}
+	return ancestors
+}
@@ -0,0 +1,58 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestExampleChecker(t *testing.T) {
+	api := apidocbuilder.Api{
+		Name: "addUser",
+		RequestBody: apidocbuilder.Parameters{
+			{Fullname: "name", Type: "string", Required: true},
+			{Fullname: "status", Type: "int", Enum: "1,2"},
+			{Fullname: "ext", Type: "object"},
+		},
+		ResponseBody: apidocbuilder.Parameters{
+			{Fullname: "code", Type: "int", Required: true},
+			{Fullname: "data.items[].id", Type: "int"},
+		},
+		Examples: apidocbuilder.Examples{
+			{Title: "新增", RequestBody: `{"status":3,"age":18,"ext":{"any":1}}`, Response: `{"code":"0","data":{"items":[{"id":1,"name":"tom","deleted":null}]}}`},
+			{Title: "文本", RequestBody: "name=tom", Response: "ok"},
+		},
+	}
+	checker := apidocbuilder.NewExampleChecker()
+	issues := checker.Check(&api)
+	kinds := make(map[string]string)
+	for _, issue := range issues {
+		require.Equal(t, "新增", issue.Example)
+		kinds[issue.Position+" "+issue.Path] = issue.Kind
+	}
+	require.Equal(t, map[string]string{
+		"requestBody name":                  apidocbuilder.VALIDATION_RULE_REQUIRED,
+		"requestBody status":                apidocbuilder.VALIDATION_RULE_ENUM,
+		"requestBody age":                   apidocbuilder.EXAMPLE_ISSUE_UNDECLARED,
+		"responseBody code":                 apidocbuilder.VALIDATION_RULE_TYPE,
+		"responseBody data.items[].name":    apidocbuilder.EXAMPLE_ISSUE_UNDECLARED,
+		"responseBody data.items[].deleted": apidocbuilder.EXAMPLE_ISSUE_UNDECLARED,
+	}, kinds)
+	require.Len(t, api.RequestBody, 3)
+
+	checker.Complement = true
+	service := apidocbuilder.Service{Apis: apidocbuilder.Apis{api}}
+	require.NotEmpty(t, checker.CheckService(&service))
+	complemented := service.Apis[0]
+	require.Len(t, complemented.RequestBody, 4)
+	require.Equal(t, apidocbuilder.Parameter{Fullname: "age", Name: "age", Type: "int", Example: "18"}, complemented.RequestBody[3])
+	require.Len(t, complemented.ResponseBody, 4)
+	require.Equal(t, "data.items[].deleted", complemented.ResponseBody[2].Fullname)
+	require.Equal(t, "any", complemented.ResponseBody[2].Type)
+
+	// 补充后不再有未定义字段
+	for _, issue := range checker.CheckService(&service) {
+		require.NotEqual(t, apidocbuilder.EXAMPLE_ISSUE_UNDECLARED, issue.Kind)
+	}
+}
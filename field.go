@@ -69,8 +69,8 @@ func Field2DocParam(f *sqlbuilder.Field) (param *Parameter, ok bool) {
 	if typ == "" {
 		typ = "string"
 	}
-	enumStr := strings.Join(enum, ", ")
-	EnumNamesStr := strings.Join(enumNames, ", ")
+	enumStr := strings.Join(enum, enumSeparator)
+	EnumNamesStr := strings.Join(enumNames, enumSeparator)
 	format := Format{}
 	format.Add(dbSchema.Format)
 	minimum := dbSchema.Minimum
@@ -163,12 +163,13 @@ func StructToFields(stru any) sqlbuilder.Fields {
 
 func getJsonTag(val reflect.StructField) (jsonTag string) {
 	tag := val.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	tag, _, _ = strings.Cut(tag, ",") // 去掉 omitempty 等选项
 	if tag == "" {
 		tag = val.Name
 	}
-	if tag == "-" {
-		tag = ""
-	}
 	return tag
 }
 
@@ -207,10 +208,20 @@ func struct2Parameters(val reflect.Value) (parameters Parameters) {
 			}
 			subParameters := struct2Parameters(subVal)
 			if len(subParameters) == 0 { // 没有子字段 说明当前字段为基础类型，直接添加本身
-				parameter := Parameter{Fullname: jsonTag, Type: attr.Type.Kind().String()}
+				parameter := Parameter{Fullname: jsonTag, Type: indirectType(attr.Type).Kind().String()}
+				structTag2Parameter(&parameter, attr)
 				parameters.Add(parameter)
 				continue
 			}
+			if hasDocTag(attr) { // 对象、数组本身有文档说明时单独成参数
+				parameter := Parameter{Fullname: jsonTag, Type: "object"}
+				switch indirectType(attr.Type).Kind() {
+				case reflect.Array, reflect.Slice:
+					parameter.Type = "array"
+				}
+				structTag2Parameter(&parameter, attr)
+				parameters.Add(parameter)
+			}
 
 			for i := 0; i < len(subParameters); i++ {
 				subParameters[i].Fullname = fmt.Sprintf("%s.%s", jsonTag, subParameters[i].Fullname)
@@ -239,3 +250,151 @@ func struct2Parameters(val reflect.Value) (parameters Parameters) {
 	parameters.FormatField()
 	return parameters
 }
+
+// 结构体文档标签,如 `json:"status" doc:"状态" desc:"用户状态" example:"1" enum:"1:启用,2:禁用" validate:"required"`
+const (
+	STRUCT_TAG_DOC      = "doc"      // 标题
+	STRUCT_TAG_DESC     = "desc"     // 说明
+	STRUCT_TAG_EXAMPLE  = "example"  // 案例
+	STRUCT_TAG_ENUM     = "enum"     // 枚举,值:名称 逗号分隔,名称可省略
+	STRUCT_TAG_FORMAT   = "format"   // 格式,多个逗号分隔
+	STRUCT_TAG_VALIDATE = "validate" // 校验规则,兼容 go-playground/validator 写法
+)
+
+// enumSeparator sqlbuilder 字段、结构体标签生成的枚举值、枚举名称使用相同的分隔符
+const enumSeparator = ", "
+
+var structDocTags = []string{STRUCT_TAG_DOC, STRUCT_TAG_DESC, STRUCT_TAG_EXAMPLE, STRUCT_TAG_ENUM, STRUCT_TAG_FORMAT, STRUCT_TAG_VALIDATE}
+
+// validateFormats validate 中表示格式的规则
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"datetime": "date-time",
+}
+
+func hasDocTag(field reflect.StructField) bool {
+	for _, name := range structDocTags {
+		if _, ok := field.Tag.Lookup(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// structTag2Parameter 将文档标签写入参数及 Schema
+func structTag2Parameter(p *Parameter, field reflect.StructField) {
+	tag := field.Tag
+	if title := tag.Get(STRUCT_TAG_DOC); title != "" {
+		p.Title, p.Schema.Title = title, title
+	}
+	if description := tag.Get(STRUCT_TAG_DESC); description != "" {
+		p.Description, p.Schema.Description = description, description
+	}
+	if example := tag.Get(STRUCT_TAG_EXAMPLE); example != "" {
+		p.Example, p.Schema.Example = example, example
+	}
+	if enum := tag.Get(STRUCT_TAG_ENUM); enum != "" {
+		setParameterEnum(p, strings.Split(enum, ","))
+	}
+	for _, format := range strings.Split(tag.Get(STRUCT_TAG_FORMAT), ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			p.SetFormat(format)
+		}
+	}
+	validate := tag.Get(STRUCT_TAG_VALIDATE)
+	if validate == "" || validate == "-" {
+		return
+	}
+	kind := indirectType(field.Type).Kind()
+	for _, rule := range strings.Split(validate, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			p.Required, p.Schema.Required = true, true
+		case "min", "gte":
+			setParameterBound(p, kind, value, true, false)
+		case "max", "lte":
+			setParameterBound(p, kind, value, false, false)
+		case "gt":
+			setParameterBound(p, kind, value, true, true)
+		case "lt":
+			setParameterBound(p, kind, value, false, true)
+		case "len":
+			setParameterBound(p, kind, value, true, false)
+			setParameterBound(p, kind, value, false, false)
+		case "oneof":
+			if p.Enum == "" { // enum 标签优先,其中有枚举名称
+				setParameterEnum(p, strings.Fields(value))
+			}
+		default:
+			if format, ok := validateFormats[name]; ok {
+				p.SetFormat(format)
+			}
+		}
+	}
+}
+
+// setParameterEnum items 为 值 或 值:名称
+func setParameterEnum(p *Parameter, items []string) {
+	values, names := make([]string, 0, len(items)), make([]string, 0, len(items))
+	hasName := false
+	for _, item := range items {
+		value, name, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if value == "" {
+			continue
+		}
+		hasName = hasName || ok
+		values = append(values, value)
+		names = append(names, firstNotEmpty(name, value))
+	}
+	p.Enum = strings.Join(values, enumSeparator)
+	p.Schema.Enum = p.Enum
+	if hasName {
+		p.EnumNames = strings.Join(names, enumSeparator)
+		p.Schema.EnumNames = p.EnumNames
+	}
+}
+
+// setParameterBound 字符串限制长度,数组限制元素个数,map 限制属性个数,其余限制取值
+func setParameterBound(p *Parameter, kind reflect.Kind, value string, lower bool, exclusive bool) {
+	n := cast.ToInt(value)
+	schema := &p.Schema
+	var minCount, maxCount *int
+	switch kind {
+	case reflect.String:
+		minCount, maxCount = &schema.MinLength, &schema.MaxLength
+	case reflect.Array, reflect.Slice:
+		minCount, maxCount = &schema.MinItems, &schema.MaxItems
+	case reflect.Map:
+		minCount, maxCount = &schema.MinProperties, &schema.MaxProperties
+	default:
+		if lower {
+			schema.Minimum, schema.ExclusiveMinimum = &n, exclusive
+		} else {
+			schema.Maximum, schema.ExclusiveMaximum = n, exclusive
+		}
+		return
+	}
+	switch {
+	case lower && exclusive:
+		*minCount = n + 1 // 个数为整数,gt=1 即 min=2
+	case lower:
+		*minCount = n
+	case exclusive:
+		*maxCount = n - 1
+	default:
+		*maxCount = n
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"github.com/suifengpiao14/apidocbuilder"
 	"github.com/suifengpiao14/sqlbuilder"
 )
@@ -119,3 +120,79 @@ func TestInterface(t *testing.T) {
 	parameters := apidocbuilder.Struct2Parameters(out)
 	fmt.Println(parameters)
 }
+
+type TaggedBook struct {
+	Title string `json:"title" doc:"书名" validate:"required,max=50"`
+}
+
+type TaggedUser struct {
+	Id       int           `json:"id,omitempty" doc:"用户ID" example:"1" validate:"required,gt=0"`
+	Nickname string        `json:"nickname" doc:"昵称" desc:"展示用的名称" validate:"min=1,max=20"`
+	Email    *string       `json:"email" format:"email"`
+	Status   int           `json:"status" doc:"状态" enum:"1:启用,2:禁用"`
+	Gender   string        `json:"gender" validate:"oneof=male female"`
+	Books    []*TaggedBook `json:"books" doc:"书籍" validate:"max=10"`
+	Internal string        `json:"-" doc:"内部字段"`
+}
+
+func TestStruct2ParametersTags(t *testing.T) {
+	ps := apidocbuilder.Struct2Parameters(TaggedUser{})
+	names := make([]string, 0, len(ps))
+	for _, p := range ps {
+		names = append(names, p.Fullname)
+	}
+	require.Equal(t, []string{"id", "nickname", "email", "status", "gender", "books", "books[].title"}, names)
+	byFullname := func(fullname string) apidocbuilder.Parameter {
+		for _, p := range ps {
+			if p.Fullname == fullname {
+				return p
+			}
+		}
+		return apidocbuilder.Parameter{}
+	}
+
+	id := byFullname("id")
+	require.Equal(t, "用户ID", id.Title)
+	require.Equal(t, "1", id.Example)
+	require.True(t, id.Required)
+	require.Equal(t, 0, *id.Schema.Minimum)
+	require.True(t, id.Schema.ExclusiveMinimum)
+
+	nickname := byFullname("nickname")
+	require.Equal(t, "展示用的名称", nickname.Description)
+	require.Equal(t, 1, nickname.Schema.MinLength)
+	require.Equal(t, 20, nickname.Schema.MaxLength)
+	require.False(t, nickname.Required)
+
+	email := byFullname("email")
+	require.Equal(t, "string", email.Type)
+	require.True(t, email.GetFormat().Has("email"))
+
+	status := byFullname("status")
+	require.Equal(t, "1, 2", status.Enum)
+	require.Equal(t, "启用, 禁用", status.EnumNames)
+
+	gender := byFullname("gender")
+	require.Equal(t, "male, female", gender.Enum)
+	require.Equal(t, "", gender.EnumNames)
+
+	books := byFullname("books")
+	require.Equal(t, "array", books.Type)
+	require.Equal(t, "书籍", books.Title)
+	require.Equal(t, 10, books.Schema.MaxItems)
+
+	title := byFullname("books[].title")
+	require.Equal(t, "书名", title.Title)
+	require.True(t, title.Required)
+	require.Equal(t, 50, title.Schema.MaxLength)
+
+	// 标签中的约束参与校验
+	errs := ps.ValidateJson([]byte(`{"id":0,"nickname":"","books":[{}]}`))
+	rules := make([]string, 0, len(errs))
+	for _, e := range errs {
+		rules = append(rules, e.Fullname+" "+e.Rule)
+	}
+	require.Contains(t, rules, "id "+apidocbuilder.VALIDATION_RULE_MINIMUM)
+	require.Contains(t, rules, "nickname "+apidocbuilder.VALIDATION_RULE_MIN_LENGTH)
+	require.Contains(t, rules, "books[].title "+apidocbuilder.VALIDATION_RULE_REQUIRED)
+}
@@ -0,0 +1,322 @@
+package apidocbuilder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+var ERROR_HAR_LOG = errors.New("invalid har log")
+
+// Har 浏览器开发者工具、抓包代理导出的 HTTP Archive(1.2)
+type Har struct {
+	Log HarLog `json:"log"`
+}
+
+type HarLog struct {
+	Version string     `json:"version"`
+	Entries []HarEntry `json:"entries"`
+}
+
+type HarEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Request         HarRequest  `json:"request"`
+	Response        HarResponse `json:"response"`
+}
+
+type HarRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	Headers     []HarNameValue `json:"headers"`
+	QueryString []HarNameValue `json:"queryString"`
+	PostData    *HarPostData   `json:"postData,omitempty"`
+}
+
+type HarResponse struct {
+	Status     int            `json:"status"`
+	StatusText string         `json:"statusText"`
+	Headers    []HarNameValue `json:"headers"`
+	Content    HarContent     `json:"content"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HarNameValue `json:"params,omitempty"`
+}
+
+type HarContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HarIgnoreHeaders 浏览器、代理自动附加的请求头,不作为接口参数
+var HarIgnoreHeaders = []string{
+	"accept", "accept-encoding", "accept-language", "cache-control", "connection", "content-length", "cookie",
+	"host", "origin", "pragma", "referer", "user-agent", "priority", "upgrade-insecure-requests",
+}
+
+// HarSensitiveNames 凭证类请求头、查询参数名称(忽略大小写及 -、_),保留参数定义,值替换为 {{名称}} 占位符,避免抓包中的密钥写入文档
+var HarSensitiveNames = []string{
+	"authorization", "proxyauthorization", "apikey", "xapikey", "key", "secret", "password", "passwd", "signature", "sign",
+}
+
+// isHarSensitive 名称在 HarSensitiveNames 中或包含 token、secret 时视为凭证
+func isHarSensitive(name string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	return containsString(HarSensitiveNames, normalized) || strings.Contains(normalized, "token") || strings.Contains(normalized, "secret")
+}
+
+// harMask 凭证值替换为同名变量占位符,调试时由环境变量等提供
+func harMask(name string) string {
+	return fmt.Sprintf("{{%s}}", strings.ToLower(name))
+}
+
+// harMaskURL 屏蔽地址中的凭证类查询参数,其余部分保持原样
+func harMaskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil || !isHarSensitive(name) {
+			continue
+		}
+		pairs[i] = fmt.Sprintf("%s=%s", key, harMask(name))
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String()
+}
+
+// Har2Service 根据抓包记录生成接口文档:按请求方法+归一化路径分组,参数由实际报文推断,每次请求响应记录为 Example
+func Har2Service(data []byte) (service *Service, warnings ImportWarnings, err error) {
+	var har Har
+	err = json.Unmarshal(data, &har)
+	if err != nil {
+		err = errors.WithMessage(err, "parse har")
+		return nil, nil, err
+	}
+	if har.Log.Entries == nil {
+		err = errors.WithMessage(ERROR_HAR_LOG, "log.entries is required")
+		return nil, nil, err
+	}
+	service = &Service{Servers: make(Servers, 0)}
+	apis := make(Apis, 0)
+	indexes := make(map[string]int) // method+path => apis 下标
+	skipped := 0
+	for i, entry := range har.Log.Entries {
+		location := fmt.Sprintf("log.entries[%d]", i)
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			warnings.Add(location, "invalid request url:%s", entry.Request.URL)
+			continue
+		}
+		if isHarStaticResource(entry) {
+			skipped++
+			continue
+		}
+		serverURL := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+		if _, exists := service.Servers.GetByName(u.Host); !exists {
+			service.Servers = append(service.Servers, Server{Name: u.Host, URL: serverURL})
+		}
+
+		method := strings.ToUpper(entry.Request.Method)
+		path, pathValues := normalizeHarPath(u.Path)
+		key := fmt.Sprintf("%s %s", method, path)
+		index, ok := indexes[key]
+		if !ok {
+			apis = append(apis, Api{
+				Name:   makeApiName(method, path),
+				Title:  fmt.Sprintf("%s %s", method, path),
+				Method: method,
+				Path:   path,
+			})
+			index = len(apis) - 1
+			indexes[key] = index
+		}
+		api := &apis[index]
+		harEntry2Api(api, entry, pathValues, location, &warnings)
+	}
+	if skipped > 0 {
+		warnings.Add("log.entries", "%d static resource entries are skipped", skipped)
+	}
+	service.AddApi(apis...)
+	return service, warnings, nil
+}
+
+// harEntry2Api 合并单次请求推断出的参数,并记录为案例
+func harEntry2Api(api *Api, entry HarEntry, pathValues map[string]string, location string, warnings *ImportWarnings) {
+	request, response := entry.Request, entry.Response
+	query := make(Parameters, 0)
+	for _, name := range sortedKeys(pathValues) {
+		p := Value2Parameter(name, pathValues[name])
+		p.Position, p.Required = OpenAPI_In_Path, true
+		query.Add(p)
+	}
+	for _, q := range request.QueryString {
+		value := q.Value
+		if isHarSensitive(q.Name) {
+			value = harMask(q.Name)
+		}
+		query.Add(Value2Parameter(q.Name, value))
+	}
+	api.Query = Query(Parameters(api.Query).MergeInferred(query...))
+
+	headers := make(map[string]string)
+	contentType := ""
+	header := make(Parameters, 0)
+	for _, h := range request.Headers {
+		name := strings.ToLower(h.Name)
+		if strings.HasPrefix(name, ":") || strings.HasPrefix(name, "sec-") || containsString(HarIgnoreHeaders, name) { // http2 伪头部、浏览器安全头部
+			continue
+		}
+		value := h.Value
+		if isHarSensitive(name) {
+			value = harMask(name)
+		}
+		headers[h.Name] = value
+		if name == strings.ToLower(HEADER_NAME_CONTENT_TYPE) {
+			contentType = h.Value
+			continue
+		}
+		header.Add(Parameter{Fullname: h.Name, Type: OpenAPI_Type_String, Example: value, Position: PARAMETER_ATTR_POSITION_ENUM_HEADER})
+	}
+	api.RequestHeader = Header(Parameters(api.RequestHeader).MergeInferred(header...))
+
+	requestBody := ""
+	if postData := request.PostData; postData != nil {
+		requestBody = postData.Text
+		if api.RequestContentType == "" {
+			api.RequestContentType = postData.MimeType
+		}
+		api.RequestBody = api.RequestBody.MergeInferred(harPostData2Parameters(*postData, location, warnings)...)
+	}
+
+	responseBody := response.Content.Text
+	if response.Content.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(responseBody)
+		if err != nil {
+			warnings.Add(location, "response content can not be decoded: %s", err.Error())
+		}
+		responseBody = string(b)
+	}
+	isSuccess := response.Status >= 200 && response.Status < 300
+	if isSuccess && api.ResponseContentType == "" {
+		api.ResponseContentType = response.Content.MimeType
+	}
+	if isSuccess && strings.Contains(response.Content.MimeType, "json") && responseBody != "" {
+		params, err := Json2Parameters(responseBody)
+		if err != nil {
+			warnings.Add(location, "json response can not be parsed: %s", err.Error())
+		} else {
+			api.ResponseBody = api.ResponseBody.MergeInferred(params...)
+		}
+	}
+
+	example := &Example{
+		Title:       fmt.Sprintf("%d %s", response.Status, entry.StartedDateTime),
+		Method:      api.Method,
+		URL:         harMaskURL(request.URL),
+		ContentType: contentType,
+		RequestBody: requestBody,
+		Response:    responseBody,
+	}
+	if len(headers) > 0 {
+		example.Headers = headers
+	}
+	if example.ContentType == "" && request.PostData != nil {
+		example.ContentType = request.PostData.MimeType
+	}
+	if !isSuccess {
+		example.Tag = cast.ToString(response.Status) // 非2xx响应用状态码作为标签,便于 mock 区分场景
+	}
+	api.Examples = append(api.Examples, example)
+}
+
+func harPostData2Parameters(postData HarPostData, location string, warnings *ImportWarnings) (params Parameters) {
+	params = make(Parameters, 0)
+	if len(postData.Params) > 0 { // 表单
+		for _, p := range postData.Params {
+			params = params.MergeInferred(Value2Parameter(p.Name, p.Value))
+		}
+		return params
+	}
+	text := strings.TrimSpace(postData.Text)
+	if text == "" {
+		return params
+	}
+	mimeType := strings.ToLower(postData.MimeType)
+	switch {
+	case strings.Contains(mimeType, "json"):
+		inferred, err := Json2Parameters(text)
+		if err != nil {
+			warnings.Add(location, "json request body can not be parsed: %s", err.Error())
+			return params
+		}
+		return inferred
+	case strings.Contains(mimeType, "x-www-form-urlencoded"):
+		values, err := url.ParseQuery(text)
+		if err != nil {
+			warnings.Add(location, "form request body can not be parsed: %s", err.Error())
+			return params
+		}
+		for _, key := range sortedKeys(values) {
+			params = params.MergeInferred(Value2Parameter(key, values.Get(key)))
+		}
+		return params
+	}
+	warnings.Add(location, "request body mime type %q is not supported, parameters are not inferred", postData.MimeType)
+	return params
+}
+
+var (
+	harNumberSegmentReg = regexp.MustCompile(`^\d+$`)
+	harUUIDSegmentReg   = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	harHashSegmentReg   = regexp.MustCompile(`^(?i)[0-9a-f]{16,}$`)
+)
+
+// normalizeHarPath 数字、uuid、hash 等路径片段替换为路径参数 {id}、{id2}...
+func normalizeHarPath(path string) (normalized string, pathValues map[string]string) {
+	pathValues = make(map[string]string)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if !harNumberSegmentReg.MatchString(segment) && !harUUIDSegmentReg.MatchString(segment) && !harHashSegmentReg.MatchString(segment) {
+			continue
+		}
+		name := "id"
+		if len(pathValues) > 0 {
+			name = fmt.Sprintf("id%d", len(pathValues)+1)
+		}
+		pathValues[name] = segment
+		segments[i] = fmt.Sprintf("{%s}", name)
+	}
+	normalized = "/" + strings.Join(segments, "/")
+	return normalized, pathValues
+}
+
+var harStaticMimeTypes = []string{"text/html", "text/css", "javascript", "image/", "font/", "video/", "audio/", "application/wasm"}
+
+func isHarStaticResource(entry HarEntry) bool {
+	mimeType := strings.ToLower(entry.Response.Content.MimeType)
+	for _, static := range harStaticMimeTypes {
+		if strings.Contains(mimeType, static) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+const harJson = `{"log": {"version": "1.2", "entries": [
+	{
+		"startedDateTime": "2024-05-01T10:00:00.000Z",
+		"request": {
+			"method": "GET",
+			"url": "https://api.shop.com/order/1001?withItems=true",
+			"headers": [{"name": ":authority", "value": "api.shop.com"}, {"name": "User-Agent", "value": "chrome"}, {"name": "X-Token", "value": "abc"}],
+			"queryString": [{"name": "withItems", "value": "true"}]
+		},
+		"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\":1001,\"remark\":null}"}}
+	},
+	{
+		"startedDateTime": "2024-05-01T10:00:01.000Z",
+		"request": {
+			"method": "GET",
+			"url": "https://api.shop.com/order/1002",
+			"headers": [{"name": "X-Token", "value": "abc"}],
+			"queryString": []
+		},
+		"response": {"status": 200, "content": {"mimeType": "application/json", "encoding": "base64", "text": "eyJpZCI6MTAwMiwicmVtYXJrIjoi5Yqg5oClIn0="}}
+	},
+	{
+		"startedDateTime": "2024-05-01T10:00:02.000Z",
+		"request": {
+			"method": "POST",
+			"url": "https://api.shop.com/order",
+			"headers": [{"name": "content-type", "value": "application/json"}],
+			"queryString": [],
+			"postData": {"mimeType": "application/json", "text": "{\"items\":[{\"sku\":\"A1\",\"count\":1}]}"}
+		},
+		"response": {"status": 400, "content": {"mimeType": "application/json", "text": "{\"code\":400}"}}
+	},
+	{
+		"startedDateTime": "2024-05-01T10:00:03.000Z",
+		"request": {"method": "GET", "url": "https://api.shop.com/logo.png", "headers": [], "queryString": []},
+		"response": {"status": 200, "content": {"mimeType": "image/png", "text": ""}}
+	}
+]}}`
+
+func TestHar2Service(t *testing.T) {
+	service, warnings, err := apidocbuilder.Har2Service([]byte(harJson))
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Len(t, service.Apis, 2)
+	require.Equal(t, "https://api.shop.com", service.Servers[0].URL)
+
+	api, err := service.GetApi("GET", "/order/{id}")
+	require.NoError(t, err)
+	require.Len(t, api.Examples, 2)
+	require.Equal(t, "path", api.Query[0].Position)
+	require.Equal(t, "int", api.Query[0].Type)
+	require.Equal(t, "withItems", api.Query[1].Fullname)
+	require.Len(t, api.RequestHeader, 1)
+	require.Equal(t, "X-Token", api.RequestHeader[0].Fullname)
+	require.Equal(t, "remark", api.ResponseBody[1].Fullname)
+	require.Equal(t, "string", api.ResponseBody[1].Type)
+	require.JSONEq(t, `{"id":1002,"remark":"加急"}`, api.Examples[1].Response)
+
+	create, err := service.GetApi("POST", "/order")
+	require.NoError(t, err)
+	require.Equal(t, "application/json", create.RequestContentType)
+	require.Equal(t, "items[].count", create.RequestBody[0].Fullname)
+	require.Empty(t, create.ResponseBody)
+	require.Equal(t, "400", create.Examples[0].Tag)
+	require.Equal(t, "application/json", create.Examples[0].ContentType)
+}
+
+func TestHar2ServiceMaskCredentials(t *testing.T) {
+	har := `{"log": {"version": "1.2", "entries": [{
+		"startedDateTime": "2024-05-01T10:00:00.000Z",
+		"request": {
+			"method": "GET",
+			"url": "https://api.shop.com/user?access_token=s3cr3t&page=1",
+			"headers": [{"name": "Authorization", "value": "Bearer eyJhbGciOiJIUzI1NiJ9.secret"}, {"name": "X-Api-Key", "value": "k-123"}, {"name": "X-Trace-Id", "value": "t1"}],
+			"queryString": [{"name": "access_token", "value": "s3cr3t"}, {"name": "page", "value": "1"}]
+		},
+		"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\":1}"}}
+	}]}}`
+	service, _, err := apidocbuilder.Har2Service([]byte(har))
+	require.NoError(t, err)
+	api, err := service.GetApi("GET", "/user")
+	require.NoError(t, err)
+
+	headers := make(map[string]string)
+	for _, h := range api.RequestHeader {
+		headers[h.Fullname] = h.Example
+	}
+	require.Equal(t, map[string]string{"Authorization": "{{authorization}}", "X-Api-Key": "{{x-api-key}}", "X-Trace-Id": "t1"}, headers)
+	require.Equal(t, "{{authorization}}", api.Examples[0].Headers["Authorization"])
+	require.Equal(t, "https://api.shop.com/user?access_token={{access_token}}&page=1", api.Examples[0].URL)
+	require.Equal(t, "{{access_token}}", api.Query[0].Example)
+
+	b, err := json.Marshal([]any{api.RequestHeader, api.Query, api.Examples})
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "eyJhbGciOiJIUzI1NiJ9")
+	require.NotContains(t, string(b), "s3cr3t")
+	require.NotContains(t, string(b), "k-123")
+}
@@ -0,0 +1,378 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	LINT_SEVERITY_ERROR   = "error"
+	LINT_SEVERITY_WARNING = "warning"
+	LINT_SEVERITY_INFO    = "info"
+	LINT_SEVERITY_OFF     = "off" // 配置中关闭规则
+)
+
+var lintSeverityRank = map[string]int{LINT_SEVERITY_INFO: 1, LINT_SEVERITY_WARNING: 2, LINT_SEVERITY_ERROR: 3}
+
+const (
+	LINT_RULE_SERVICE_DESCRIPTION      = "service-description"
+	LINT_RULE_API_NAME                 = "api-name"
+	LINT_RULE_API_DUPLICATE            = "api-duplicate"
+	LINT_RULE_API_TITLE                = "api-title"
+	LINT_RULE_API_DESCRIPTION          = "api-description"
+	LINT_RULE_API_EXAMPLES             = "api-examples"
+	LINT_RULE_PARAMETER_TYPE           = "parameter-type"
+	LINT_RULE_PARAMETER_REQUIRED_TITLE = "parameter-required-title"
+	LINT_RULE_PARAMETER_ENUM_NAMES     = "parameter-enum-names"
+	LINT_RULE_PARAMETER_DESCRIPTION    = "parameter-description"
+)
+
+var (
+	ERROR_NOT_FOUND_LINT_RULE   = errors.New("not found lint rule")
+	ERROR_INVALID_LINT_SEVERITY = errors.New("invalid lint severity")
+)
+
+// LintRule 检查规则,按层级实现 Service、Api、Parameter 中的一个或多个,返回问题描述
+type LintRule struct {
+	Name        string
+	Severity    string
+	Description string
+	Service     func(service Service) (messages []string)
+	Api         func(api Api) (messages []string)
+	// position 为参数所在位置(requestHeader、query、requestBody、responseHeader、responseBody)
+	Parameter func(api Api, position string, p Parameter) (messages []string)
+}
+
+type LintRules []LintRule
+
+// LintRulesDefault 内置规则
+var LintRulesDefault = LintRules{
+	{
+		Name: LINT_RULE_SERVICE_DESCRIPTION, Severity: LINT_SEVERITY_WARNING, Description: "服务需要描述",
+		Service: func(service Service) (messages []string) {
+			return lintIf(strings.TrimSpace(service.Description) == "", "服务缺少描述")
+		},
+	},
+	{
+		Name: LINT_RULE_API_NAME, Severity: LINT_SEVERITY_ERROR, Description: "接口需要名称,文档链接、调试表单依赖名称",
+		Api: func(api Api) (messages []string) {
+			return lintIf(strings.TrimSpace(api.Name) == "", "接口缺少名称")
+		},
+	},
+	{
+		Name: LINT_RULE_API_DUPLICATE, Severity: LINT_SEVERITY_ERROR, Description: "接口名称、method+path 不能重复",
+		Service: lintDuplicateApis,
+	},
+	{
+		Name: LINT_RULE_API_TITLE, Severity: LINT_SEVERITY_WARNING, Description: "接口需要标题",
+		Api: func(api Api) (messages []string) {
+			return lintIf(strings.TrimSpace(api.Title) == "", "接口缺少标题")
+		},
+	},
+	{
+		Name: LINT_RULE_API_DESCRIPTION, Severity: LINT_SEVERITY_WARNING, Description: "接口需要描述",
+		Api: func(api Api) (messages []string) {
+			return lintIf(strings.TrimSpace(api.Description) == "", "接口缺少描述")
+		},
+	},
+	{
+		Name: LINT_RULE_API_EXAMPLES, Severity: LINT_SEVERITY_WARNING, Description: "接口需要案例",
+		Api: func(api Api) (messages []string) {
+			return lintIf(len(api.Examples) == 0, "接口缺少案例")
+		},
+	},
+	{
+		Name: LINT_RULE_PARAMETER_TYPE, Severity: LINT_SEVERITY_WARNING, Description: "参数需要类型",
+		Parameter: func(api Api, position string, p Parameter) (messages []string) {
+			return lintIf(p.Type == "" && p.Schema.Type == "", "参数缺少类型")
+		},
+	},
+	{
+		Name: LINT_RULE_PARAMETER_REQUIRED_TITLE, Severity: LINT_SEVERITY_ERROR, Description: "必填参数需要标题",
+		Parameter: func(api Api, position string, p Parameter) (messages []string) {
+			return lintIf(parameterRequired(p) && p.Title == "" && p.Schema.Title == "", "必填参数缺少标题")
+		},
+	},
+	{
+		Name: LINT_RULE_PARAMETER_ENUM_NAMES, Severity: LINT_SEVERITY_ERROR, Description: "枚举名称数量需与枚举值一致",
+		Parameter: func(api Api, position string, p Parameter) (messages []string) {
+			enumNames := firstNotEmpty(p.EnumNames, p.Schema.EnumNames)
+			enum := parameterEnum(p)
+			if enumNames == "" {
+				return nil
+			}
+			names := splitEnum(enumNames)
+			return lintIf(len(names) != len(enum), fmt.Sprintf("枚举值 %d 个,枚举名称 %d 个", len(enum), len(names)))
+		},
+	},
+	{
+		Name: LINT_RULE_PARAMETER_DESCRIPTION, Severity: LINT_SEVERITY_INFO, Description: "参数需要标题或描述",
+		Parameter: func(api Api, position string, p Parameter) (messages []string) {
+			return lintIf(p.TitleOrDescription() == "" && p.Schema.Title == "" && p.Schema.Description == "", "参数缺少标题和描述")
+		},
+	},
+}
+
+func lintIf(condition bool, message string) (messages []string) {
+	if condition {
+		return []string{message}
+	}
+	return nil
+}
+
+func lintDuplicateApis(service Service) (messages []string) {
+	names, routes := make(map[string]int), make(map[string]int)
+	for _, api := range service.Apis {
+		if api.Name != "" {
+			names[strings.ToLower(api.Name)]++
+			if names[strings.ToLower(api.Name)] == 2 {
+				messages = append(messages, fmt.Sprintf("接口名称重复:%s", api.Name))
+			}
+		}
+		route := fmt.Sprintf("%s %s", strings.ToUpper(api.Method), api.Path)
+		routes[strings.ToLower(route)]++
+		if routes[strings.ToLower(route)] == 2 {
+			messages = append(messages, fmt.Sprintf("接口路由重复:%s", route))
+		}
+	}
+	return messages
+}
+
+// LintFinding 一条检查结果,Api、Fullname 为空时分别表示服务级、接口级问题
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Api      string `json:"api,omitempty"`
+	Method   string `json:"method,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Position string `json:"position,omitempty"`
+	Fullname string `json:"fullname,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Location 接口名称加参数全称
+func (f LintFinding) Location() (location string) {
+	location = f.Api
+	if f.Fullname != "" {
+		location = fmt.Sprintf("%s %s.%s", location, f.Position, f.Fullname)
+	}
+	return strings.TrimSpace(location)
+}
+
+type LintFindings []LintFinding
+
+// Count 某个级别的数量
+func (fs LintFindings) Count(severity string) (count int) {
+	for _, f := range fs {
+		if f.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// AtLeast 级别不低于 severity 的结果
+func (fs LintFindings) AtLeast(severity string) (findings LintFindings) {
+	findings = make(LintFindings, 0)
+	for _, f := range fs {
+		if lintSeverityRank[f.Severity] >= lintSeverityRank[severity] {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Linter 文档检查,规则可单独启用、关闭或调整级别
+type Linter struct {
+	rules LintRules
+	// 不低于该级别的结果使检查失败,默认 error
+	FailSeverity string
+}
+
+func NewLinter() (linter *Linter) {
+	rules := make(LintRules, len(LintRulesDefault))
+	copy(rules, LintRulesDefault)
+	return &Linter{rules: rules, FailSeverity: LINT_SEVERITY_ERROR}
+}
+
+// AddRule 增加自定义规则,同名规则覆盖
+func (l *Linter) AddRule(rules ...LintRule) *Linter {
+	for _, rule := range rules {
+		if i := l.ruleIndex(rule.Name); i >= 0 {
+			l.rules[i] = rule
+			continue
+		}
+		l.rules = append(l.rules, rule)
+	}
+	return l
+}
+
+func (l *Linter) Rules() (rules LintRules) {
+	return l.rules
+}
+
+func (l *Linter) ruleIndex(name string) int {
+	for i, rule := range l.rules {
+		if rule.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Configure 规则名称 -> 级别(error、warning、info、off),适合从 CI 配置文件读取
+func (l *Linter) Configure(config map[string]string) (err error) {
+	for _, name := range sortedKeys(config) {
+		severity := config[name]
+		i := l.ruleIndex(name)
+		if i < 0 {
+			err = errors.WithMessagef(ERROR_NOT_FOUND_LINT_RULE, "rule:%s", name)
+			return err
+		}
+		if _, ok := lintSeverityRank[severity]; !ok && severity != LINT_SEVERITY_OFF {
+			err = errors.WithMessagef(ERROR_INVALID_LINT_SEVERITY, "rule:%s,severity:%s", name, severity)
+			return err
+		}
+		l.rules[i].Severity = severity
+	}
+	return nil
+}
+
+func (l *Linter) Disable(names ...string) (err error) {
+	config := make(map[string]string)
+	for _, name := range names {
+		config[name] = LINT_SEVERITY_OFF
+	}
+	return l.Configure(config)
+}
+
+// Enable 重新启用规则,使用内置规则的默认级别
+func (l *Linter) Enable(names ...string) (err error) {
+	for _, name := range names {
+		i := l.ruleIndex(name)
+		if i < 0 {
+			err = errors.WithMessagef(ERROR_NOT_FOUND_LINT_RULE, "rule:%s", name)
+			return err
+		}
+		if l.rules[i].Severity != LINT_SEVERITY_OFF {
+			continue
+		}
+		l.rules[i].Severity = LINT_SEVERITY_WARNING
+		for _, rule := range LintRulesDefault {
+			if rule.Name == name {
+				l.rules[i].Severity = rule.Severity
+			}
+		}
+	}
+	return nil
+}
+
+// Lint 检查服务及其所有接口、参数
+func (l *Linter) Lint(service Service) (report LintReport) {
+	report = LintReport{Service: service.Name, FailSeverity: l.FailSeverity, Findings: make(LintFindings, 0)}
+	for _, rule := range l.enabledRules() {
+		if rule.Service == nil {
+			continue
+		}
+		for _, message := range rule.Service(service) {
+			report.Findings = append(report.Findings, LintFinding{Rule: rule.Name, Severity: rule.Severity, Message: message})
+		}
+	}
+	for _, api := range service.Apis {
+		report.Findings = append(report.Findings, l.LintApi(api)...)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return lintSeverityRank[report.Findings[i].Severity] > lintSeverityRank[report.Findings[j].Severity]
+	})
+	return report
+}
+
+// LintApi 检查单个接口及其参数
+func (l *Linter) LintApi(api Api) (findings LintFindings) {
+	findings = make(LintFindings, 0)
+	rules := l.enabledRules()
+	add := func(rule LintRule, position string, fullname string, messages []string) {
+		for _, message := range messages {
+			findings = append(findings, LintFinding{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Api:      api.Name,
+				Method:   strings.ToUpper(api.Method),
+				Path:     api.Path,
+				Position: position,
+				Fullname: fullname,
+				Message:  message,
+			})
+		}
+	}
+	for _, rule := range rules {
+		if rule.Api != nil {
+			add(rule, "", "", rule.Api(api))
+		}
+	}
+	for _, group := range apiParameterGroups(api) {
+		for _, p := range group.parameters {
+			for _, rule := range rules {
+				if rule.Parameter != nil {
+					add(rule, group.position, parameterDiffKey(p), rule.Parameter(api, group.position, p))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func (l *Linter) enabledRules() (rules LintRules) {
+	rules = make(LintRules, 0, len(l.rules))
+	for _, rule := range l.rules {
+		if rule.Severity != LINT_SEVERITY_OFF {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+type apiParameterGroup struct {
+	position   string
+	parameters Parameters
+}
+
+// apiParameterGroups 接口各位置的参数,位置名称与 Api 的 json 字段一致
+func apiParameterGroups(api Api) (groups []apiParameterGroup) {
+	return []apiParameterGroup{
+		{CHANGE_POSITION_REQUEST_HEADER, Parameters(api.RequestHeader)},
+		{CHANGE_POSITION_QUERY, Parameters(api.Query)},
+		{CHANGE_POSITION_REQUEST_BODY, api.RequestBody},
+		{CHANGE_POSITION_RESPONSE_HEADER, Parameters(api.ResponseHeader)},
+		{CHANGE_POSITION_RESPONSE_BODY, api.ResponseBody},
+	}
+}
+
+// LintReport 检查报告
+type LintReport struct {
+	Service      string       `json:"service"`
+	FailSeverity string       `json:"failSeverity"`
+	Findings     LintFindings `json:"findings"`
+}
+
+// Passed 没有不低于 FailSeverity 的结果,CI 中据此决定是否通过
+func (r LintReport) Passed() bool {
+	return len(r.Findings.AtLeast(firstNotEmpty(r.FailSeverity, LINT_SEVERITY_ERROR))) == 0
+}
+
+func (r LintReport) Markdown() (out []byte, err error) {
+	return ExecTpl(TPL_NAME_MARKDOWN_LINT, r)
+}
+
+func (r LintReport) Json() (out []byte, err error) {
+	out, err = json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		err = errors.WithMessage(err, "marshal lint report")
+		return nil, err
+	}
+	return out, nil
+}
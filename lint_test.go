@@ -0,0 +1,77 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestLinter(t *testing.T) {
+	service := apidocbuilder.Service{Name: "user", Apis: apidocbuilder.Apis{
+		{Name: "addUser", Title: "新增用户", Description: "新增", Method: "POST", Path: "/user/add",
+			Examples: apidocbuilder.Examples{{Title: "默认"}},
+			RequestBody: apidocbuilder.Parameters{
+				{Fullname: "name", Type: "string", Required: true},
+				{Fullname: "status", Type: "int", Title: "状态", Enum: "1,2", EnumNames: "启用"},
+			},
+		},
+		{Name: "addUser", Method: "POST", Path: "/user/create"},
+	}}
+
+	linter := apidocbuilder.NewLinter()
+	report := linter.Lint(service)
+	require.False(t, report.Passed())
+	rules := make(map[string]apidocbuilder.LintFinding)
+	for _, f := range report.Findings {
+		rules[strings.TrimSpace(f.Rule+" "+f.Location())] = f
+	}
+	require.Contains(t, rules, "service-description")
+	require.Contains(t, rules, "api-duplicate")
+	require.Contains(t, rules, "api-examples addUser")
+	require.Contains(t, rules, "parameter-required-title addUser requestBody.name")
+	require.Equal(t, apidocbuilder.LINT_SEVERITY_ERROR, rules["parameter-enum-names addUser requestBody.status"].Severity)
+	require.Equal(t, "/user/add", rules["parameter-enum-names addUser requestBody.status"].Path)
+	require.Equal(t, apidocbuilder.LINT_SEVERITY_ERROR, report.Findings[0].Severity)
+
+	md, err := report.Markdown()
+	require.NoError(t, err)
+	require.Contains(t, string(md), "**结果:** 失败")
+	require.Contains(t, string(md), "|error|parameter-enum-names|addUser requestBody.status|枚举值 2 个,枚举名称 1 个|")
+	b, err := report.Json()
+	require.NoError(t, err)
+	var decoded apidocbuilder.LintReport
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, report, decoded)
+
+	// 关闭、调整级别后通过
+	require.NoError(t, linter.Configure(map[string]string{
+		apidocbuilder.LINT_RULE_API_DUPLICATE:        apidocbuilder.LINT_SEVERITY_OFF,
+		apidocbuilder.LINT_RULE_PARAMETER_ENUM_NAMES: apidocbuilder.LINT_SEVERITY_WARNING,
+	}))
+	require.NoError(t, linter.Disable(apidocbuilder.LINT_RULE_PARAMETER_REQUIRED_TITLE))
+	report = linter.Lint(service)
+	require.True(t, report.Passed())
+	require.Equal(t, 0, report.Findings.Count(apidocbuilder.LINT_SEVERITY_ERROR))
+
+	require.NoError(t, linter.Enable(apidocbuilder.LINT_RULE_API_DUPLICATE))
+	require.False(t, linter.Lint(service).Passed())
+
+	require.ErrorIs(t, linter.Configure(map[string]string{"notExists": "error"}), apidocbuilder.ERROR_NOT_FOUND_LINT_RULE)
+	require.ErrorIs(t, linter.Configure(map[string]string{apidocbuilder.LINT_RULE_API_TITLE: "fatal"}), apidocbuilder.ERROR_INVALID_LINT_SEVERITY)
+
+	// 自定义规则
+	linter.AddRule(apidocbuilder.LintRule{
+		Name: "path-prefix", Severity: apidocbuilder.LINT_SEVERITY_ERROR,
+		Api: func(api apidocbuilder.Api) []string {
+			if api.Path != "/user/add" {
+				return []string{"路径需以 /user/add 开头"}
+			}
+			return nil
+		},
+	})
+	findings := linter.LintApi(service.Apis[1])
+	require.Contains(t, findings, apidocbuilder.LintFinding{Rule: "path-prefix", Severity: "error", Api: "addUser", Method: "POST", Path: "/user/create", Message: "路径需以 /user/add 开头"})
+}
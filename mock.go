@@ -0,0 +1,136 @@
+package apidocbuilder
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	MOCK_HEADER_TAG = "X-Mock-Tag" // 请求头指定案例标签,响应头回写实际使用的标签
+	MOCK_QUERY_TAG  = "_mockTag"   // query 指定案例标签,优先级低于请求头
+)
+
+// MockHandler 根据接口案例返回模拟数据,同一标签的案例组成一个场景,前端整个流程可以使用一致的数据
+type MockHandler struct {
+	service Service
+	// 未指定标签时使用的默认标签
+	DefaultTag string
+	// 无案例时生成模拟数据的随机种子
+	Seed int64
+}
+
+func NewMockHandler(service Service) (handler *MockHandler) {
+	return &MockHandler{service: service, Seed: MockSeedDefault}
+}
+
+func (h *MockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setMockCorsHeader(w, r)
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" { // 跨域预检
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	api, _, err := h.service.Apis.Match(r.Method, r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	tag := h.Tag(r)
+	example, ok := api.Examples.GetByTag(tag)
+	body := ""
+	if ok {
+		body = example.Response
+	} else {
+		mockExample, err := api.MockExample(h.Seed) // 无案例时根据参数定义生成
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = mockExample.Response
+	}
+
+	for _, header := range api.ResponseHeader {
+		if value := header.Value(); value != "" {
+			w.Header().Set(header.Fullname, value)
+		}
+	}
+	contentType := api.ResponseContentType
+	if contentType == "" {
+		contentType = Header_Value_Content_Type_Json
+	}
+	w.Header().Set(HEADER_NAME_CONTENT_TYPE, contentType)
+	status := http.StatusOK
+	if ok {
+		w.Header().Set(MOCK_HEADER_TAG, example.Tag)
+		status = mockStatus(example.Tag)
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// Tag 获取请求指定的案例标签:请求头 > query > 默认标签
+func (h *MockHandler) Tag(r *http.Request) (tag string) {
+	tag = r.Header.Get(MOCK_HEADER_TAG)
+	if tag == "" {
+		tag = r.URL.Query().Get(MOCK_QUERY_TAG)
+	}
+	if tag == "" {
+		tag = h.DefaultTag
+	}
+	return tag
+}
+
+// GetByTag 优先返回相同标签的案例,没有时返回无标签的案例,再没有则返回第一个;忽略空案例
+func (examples Examples) GetByTag(tag string) (example *Example, ok bool) {
+	if tag != "" {
+		for _, e := range examples {
+			if e != nil && strings.EqualFold(e.Tag, tag) {
+				return e, true
+			}
+		}
+	}
+	for _, e := range examples {
+		if e != nil && e.Tag == "" {
+			return e, true
+		}
+	}
+	for _, e := range examples {
+		if e != nil {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+var mockStatusTagReg = regexp.MustCompile(`^[1-5]\d{2}$`)
+
+// mockStatus 标签为http状态码时(导入非2xx响应时的标签),使用该状态码响应
+func mockStatus(tag string) (status int) {
+	if mockStatusTagReg.MatchString(tag) {
+		return cast.ToInt(tag)
+	}
+	return http.StatusOK
+}
+
+// setMockCorsHeader 前端页面通常与 mock 服务不同源,允许跨域请求;mock 数据不需要凭证,不允许携带 cookie 的跨域读取
+func setMockCorsHeader(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Expose-Headers", MOCK_HEADER_TAG)
+	if r.Method != http.MethodOptions {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS")
+	allowHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if allowHeaders == "" {
+		allowHeaders = MOCK_HEADER_TAG
+	}
+	w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+}
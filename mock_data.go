@@ -0,0 +1,451 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// MockSeedDefault 默认随机种子,相同种子生成相同数据,文档和测试可复现
+const MockSeedDefault int64 = 1
+
+// mockPatternRetry 正则生成的字符串长度不满足限制时的重试次数
+const mockPatternRetry = 20
+
+var ERROR_MOCK_PATTERN_LENGTH = errors.New("pattern can not generate value within length limits")
+
+// MockGenerator 根据参数定义生成满足约束(枚举、格式、长度、范围、正则、数组项数)的随机数据
+type MockGenerator struct {
+	rand *rand.Rand
+	// 忽略参数中的示例值、默认值,全部随机生成
+	IgnoreExample bool
+	// 数组未限制项数时生成的最大项数
+	MaxItems int
+}
+
+func NewMockGenerator(seed int64) (g *MockGenerator) {
+	return &MockGenerator{rand: rand.New(rand.NewSource(seed)), MaxItems: 3}
+}
+
+// Parameters 生成参数对应的数据(对象)
+func (g *MockGenerator) Parameters(ps Parameters) (value any, err error) {
+	return g.Schema(ps.OpenAPISchema())
+}
+
+// ParametersJson 生成参数对应的json数据
+func (g *MockGenerator) ParametersJson(ps Parameters) (jsonStr string, err error) {
+	if len(ps) == 0 {
+		return "", nil
+	}
+	value, err := g.Parameters(ps)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Parameter 生成单个参数的值
+func (g *MockGenerator) Parameter(p Parameter) (value any, err error) {
+	p.FormatField()
+	return g.value(p.Name, parameter2OpenAPISchema(p))
+}
+
+// Schema 生成 schema 对应的数据
+func (g *MockGenerator) Schema(schema *OpenAPISchema) (value any, err error) {
+	return g.value("", schema)
+}
+
+func (g *MockGenerator) value(name string, schema *OpenAPISchema) (value any, err error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if len(schema.AllOf) > 0 {
+		schema = mergeOpenAPIAllOf(schema)
+	}
+	alternatives := make([]*OpenAPISchema, 0, len(schema.OneOf)+len(schema.AnyOf))
+	alternatives = append(append(alternatives, schema.OneOf...), schema.AnyOf...)
+	if len(alternatives) > 0 {
+		return g.value(name, alternatives[g.rand.Intn(len(alternatives))])
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rand.Intn(len(schema.Enum))], nil
+	}
+	if !g.IgnoreExample && schema.Example != nil {
+		return schema.Example, nil
+	}
+	if !g.IgnoreExample && schema.Default != nil {
+		return schema.Default, nil
+	}
+	typ := schema.Type.Main()
+	if typ == "" {
+		switch {
+		case len(schema.Properties) > 0:
+			typ = OpenAPI_Type_Object
+		case schema.Items != nil:
+			typ = OpenAPI_Type_Array
+		default:
+			typ = OpenAPI_Type_String
+		}
+	}
+	switch typ {
+	case OpenAPI_Type_Object:
+		obj := make(map[string]any)
+		for _, key := range sortedKeys(schema.Properties) {
+			obj[key], err = g.value(key, schema.Properties[key])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	case OpenAPI_Type_Array:
+		return g.array(name, schema)
+	case OpenAPI_Type_Integer:
+		return g.integer(schema), nil
+	case OpenAPI_Type_Number:
+		return g.number(schema), nil
+	case OpenAPI_Type_Boolean:
+		return g.rand.Intn(2) == 1, nil
+	case OpenAPI_Type_Null:
+		return nil, nil
+	}
+	return g.string(name, schema)
+}
+
+func (g *MockGenerator) array(name string, schema *OpenAPISchema) (arr []any, err error) {
+	minItems, maxItems := 1, g.MaxItems
+	if schema.MinItems != nil {
+		minItems = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		maxItems = *schema.MaxItems
+	}
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+	count := minItems + g.rand.Intn(maxItems-minItems+1)
+	arr = make([]any, 0, count)
+	seen := make(map[string]bool)
+	for retry := 0; len(arr) < count && retry < count*10; retry++ {
+		item, err := g.value(name, schema.Items)
+		if err != nil {
+			return nil, err
+		}
+		if schema.UniqueItems {
+			key := fmt.Sprintf("%v", item)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		arr = append(arr, item)
+	}
+	return arr, nil
+}
+
+func (g *MockGenerator) integer(schema *OpenAPISchema) (value int64) {
+	minimum, maximum := g.bounds(schema, 1, 1000, 1)
+	lo, hi := int64(math.Ceil(minimum)), int64(math.Floor(maximum))
+	if hi < lo {
+		hi = lo
+	}
+	value = lo + g.rand.Int63n(hi-lo+1)
+	if schema.MultipleOf != nil && *schema.MultipleOf >= 1 {
+		multipleOf := int64(*schema.MultipleOf)
+		value = value / multipleOf * multipleOf
+		if value < lo {
+			value += multipleOf
+		}
+	}
+	return value
+}
+
+func (g *MockGenerator) number(schema *OpenAPISchema) (value float64) {
+	minimum, maximum := g.bounds(schema, 0, 1000, 0.01)
+	value = minimum + g.rand.Float64()*(maximum-minimum)
+	value = math.Round(value*100) / 100 // 保留两位小数,更接近真实金额、比例
+	if value < minimum || value > maximum {
+		value = minimum
+	}
+	return value
+}
+
+// bounds 计算取值范围,exclusive 时向内收缩 step
+func (g *MockGenerator) bounds(schema *OpenAPISchema, defaultMin float64, defaultMax float64, step float64) (minimum float64, maximum float64) {
+	minimum, maximum = defaultMin, defaultMax
+	hasMin, hasMax := schema.Minimum != nil, schema.Maximum != nil
+	if hasMin {
+		minimum = *schema.Minimum
+	}
+	if hasMax {
+		maximum = *schema.Maximum
+	}
+	if v, ok := schema.ExclusiveMinimum.(float64); ok { // 3.1 数值形式
+		minimum, hasMin = v+step, true
+	} else if b, ok := schema.ExclusiveMinimum.(bool); ok && b {
+		minimum += step
+	}
+	if v, ok := schema.ExclusiveMaximum.(float64); ok {
+		maximum, hasMax = v-step, true
+	} else if b, ok := schema.ExclusiveMaximum.(bool); ok && b {
+		maximum -= step
+	}
+	if hasMin && !hasMax && maximum < minimum {
+		maximum = minimum + defaultMax
+	}
+	if hasMax && !hasMin && minimum > maximum {
+		minimum = maximum - defaultMax
+	}
+	if maximum < minimum {
+		maximum = minimum
+	}
+	return minimum, maximum
+}
+
+func (g *MockGenerator) string(name string, schema *OpenAPISchema) (value string, err error) {
+	if schema.Pattern != "" {
+		minLength, maxLength := 0, -1
+		if schema.MinLength != nil {
+			minLength = *schema.MinLength
+		}
+		if schema.MaxLength != nil {
+			maxLength = *schema.MaxLength
+		}
+		value, err = g.pattern(schema.Pattern, minLength, maxLength)
+		if err != nil {
+			err = errors.WithMessagef(err, "mock %s pattern", name)
+			return "", err
+		}
+		return value, nil
+	}
+	format := strings.ToLower(schema.Format)
+	if format == "" {
+		format = mockFormatByName(name)
+	}
+	if value, ok := g.format(format); ok {
+		return value, nil
+	}
+	minLength, maxLength := 6, 12
+	if schema.MinLength != nil {
+		minLength = *schema.MinLength
+		if maxLength < minLength {
+			maxLength = minLength + 6
+		}
+	}
+	if schema.MaxLength != nil {
+		maxLength = *schema.MaxLength
+		if minLength > maxLength {
+			minLength = maxLength
+		}
+	}
+	length := minLength + g.rand.Intn(maxLength-minLength+1)
+	return g.letters(length), nil
+}
+
+func (g *MockGenerator) format(format string) (value string, ok bool) {
+	switch format {
+	case "email":
+		return fmt.Sprintf("%s@%s.com", g.letters(6), g.pick(mockDomains)), true
+	case "phone", "tel", "mobile":
+		return fmt.Sprintf("1%d%09d", 3+g.rand.Intn(7), g.rand.Intn(1000000000)), true
+	case "uri", "url":
+		return fmt.Sprintf("https://www.%s.com/%s", g.pick(mockDomains), g.letters(6)), true
+	case "date":
+		return g.time().Format("2006-01-02"), true
+	case "date-time", "datetime":
+		return g.time().Format(time.RFC3339), true
+	case "time":
+		return g.time().Format("15:04:05"), true
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		b[6], b[8] = (b[6]&0x0f)|0x40, (b[8]&0x3f)|0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "ipv4", "ip":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+g.rand.Intn(223), g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	}
+	return "", false
+}
+
+// mockFormatByName 未声明格式时根据字段名推断,使数据更接近真实
+func mockFormatByName(name string) (format string) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "email"):
+		return "email"
+	case strings.Contains(lower, "phone"), strings.Contains(lower, "mobile"), lower == "tel":
+		return "phone"
+	case strings.HasSuffix(lower, "url"), strings.HasSuffix(lower, "link"):
+		return "uri"
+	case strings.HasSuffix(lower, "date"):
+		return "date"
+	case strings.HasSuffix(lower, "time"), strings.HasSuffix(name, "At"), strings.HasSuffix(lower, "_at"):
+		return "date-time"
+	}
+	return ""
+}
+
+var mockDomains = []string{"example", "test", "demo", "sample"}
+
+const mockLetters = "abcdefghijklmnopqrstuvwxyz"
+
+func (g *MockGenerator) letters(length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = mockLetters[g.rand.Intn(len(mockLetters))]
+	}
+	return string(b)
+}
+
+func (g *MockGenerator) pick(arr []string) string {
+	return arr[g.rand.Intn(len(arr))]
+}
+
+// time 固定区间内的时间,不依赖当前时间,保证可复现
+func (g *MockGenerator) time() time.Time {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.rand.Int63n(5*365*24)) * time.Hour).Add(time.Duration(g.rand.Intn(3600)) * time.Second)
+}
+
+// pattern 根据正则生成匹配的字符串,maxLength 小于 0 时不限长度;
+// 长度不满足时不限次数的重复(* + {n,})改为固定额外次数,按生成结果的长度二分查找满足限制的次数
+func (g *MockGenerator) pattern(pattern string, minLength int, maxLength int) (value string, err error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	re = re.Simplify()
+	repeat := mockRepeat{max: g.MaxItems} // 首次在默认范围内随机
+	low, high := 0, math.MaxInt
+	for i := 0; i < mockPatternRetry && low <= high; i++ {
+		var w strings.Builder
+		g.regexp(&w, re, repeat)
+		value = w.String()
+		length := utf8.RuneCountInString(value)
+		tooShort, tooLong := length < minLength, maxLength >= 0 && length > maxLength
+		if !tooShort && !tooLong {
+			return value, nil
+		}
+		if i > 0 {
+			if tooShort {
+				low = repeat.min + 1
+			} else {
+				high = repeat.min - 1
+			}
+		}
+		count := 0
+		switch {
+		case i == 0:
+		case high == math.MaxInt:
+			count = low*2 + 1
+		default:
+			count = low + (high-low)/2
+		}
+		repeat = mockRepeat{min: count, max: count}
+	}
+	err = errors.WithMessagef(ERROR_MOCK_PATTERN_LENGTH, "pattern:%s,minLength:%d,maxLength:%d", pattern, minLength, maxLength)
+	return "", err
+}
+
+// mockRepeat * + {n,} 等不限次数的重复在最少次数之外额外重复的次数范围
+type mockRepeat struct {
+	min int
+	max int
+}
+
+func (g *MockGenerator) regexp(w *strings.Builder, re *syntax.Regexp, repeat mockRepeat) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		w.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		w.WriteRune(g.charClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		w.WriteByte(mockLetters[g.rand.Intn(len(mockLetters))])
+	case syntax.OpCapture:
+		g.regexp(w, re.Sub[0], repeat)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(w, sub, repeat)
+		}
+	case syntax.OpAlternate:
+		g.regexp(w, re.Sub[g.rand.Intn(len(re.Sub))], repeat)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		minCount, maxCount := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			minCount, maxCount = repeat.min, repeat.max
+		case syntax.OpPlus:
+			minCount, maxCount = max(1, repeat.min), max(1, repeat.max)
+		case syntax.OpQuest:
+			minCount, maxCount = 0, 1
+		}
+		if maxCount < 0 { // {n,} 无上限
+			minCount, maxCount = minCount+repeat.min, minCount+repeat.max
+		}
+		count := minCount + g.rand.Intn(maxCount-minCount+1)
+		for i := 0; i < count; i++ {
+			g.regexp(w, re.Sub[0], repeat)
+		}
+	}
+	// 其余为 ^ $ \b 等零宽断言,不产生字符
+}
+
+// charClass 在字符区间中随机取字符,优先取可打印的 ascii 字符
+func (g *MockGenerator) charClass(ranges []rune) rune {
+	printable := make([]rune, 0, len(ranges))
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 'a'
+	}
+	i := g.rand.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(g.rand.Intn(int(hi-lo)+1))
+}
+
+// MockExample 无案例时生成模拟案例,种子结合请求方法、路径,同一接口每次生成相同数据
+func (api Api) MockExample(seed int64) (example Example, err error) {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToUpper(api.Method) + " " + api.Path))
+	g := NewMockGenerator(seed ^ int64(h.Sum64()))
+	example = Example{
+		Title:       api.Title,
+		Method:      api.Method,
+		ContentType: api.RequestContentType,
+	}
+	if api.RequestContentType == "" || strings.Contains(strings.ToLower(api.RequestContentType), "json") {
+		example.RequestBody, err = g.ParametersJson(api.RequestBody)
+		if err != nil {
+			return example, err
+		}
+	}
+	example.Response, err = g.ParametersJson(api.ResponseBody)
+	if err != nil {
+		return example, err
+	}
+	return example, nil
+}
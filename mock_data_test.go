@@ -0,0 +1,77 @@
+package apidocbuilder_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+	"github.com/tidwall/gjson"
+)
+
+func TestMockGeneratorParametersJson(t *testing.T) {
+	minimum := 10
+	ps := apidocbuilder.Parameters{
+		{Fullname: "status", Type: "int", Enum: "1,2"},
+		{Fullname: "email", Type: "string", Schema: apidocbuilder.Schema{Format: apidocbuilder.Format{"email"}}},
+		{Fullname: "birthday", Type: "string", Schema: apidocbuilder.Schema{Format: apidocbuilder.Format{"date"}}},
+		{Fullname: "mobile", Type: "string", Schema: apidocbuilder.Schema{Format: apidocbuilder.Format{"phone"}}},
+		{Fullname: "homepage", Type: "string", Schema: apidocbuilder.Schema{Format: apidocbuilder.Format{"url"}}},
+		{Fullname: "nickname", Type: "string", Schema: apidocbuilder.Schema{MinLength: 2, MaxLength: 4}},
+		{Fullname: "age", Type: "int", Schema: apidocbuilder.Schema{Minimum: &minimum, Maximum: 20}},
+		{Fullname: "code", Type: "string", Schema: apidocbuilder.Schema{Pattern: `^[A-Z]{2}-\d{4}$`}},
+		{Fullname: "tags", Type: "array", Schema: apidocbuilder.Schema{MinItems: 2, MaxItems: 2}},
+		{Fullname: "tags[]", Type: "string"},
+		{Fullname: "items[].price", Type: "number"},
+	}
+	g := apidocbuilder.NewMockGenerator(42)
+	s, err := g.ParametersJson(ps)
+	require.NoError(t, err)
+
+	result := gjson.Parse(s)
+	require.Contains(t, []int64{1, 2}, result.Get("status").Int())
+	require.Regexp(t, `^[a-z]+@[a-z]+\.com$`, result.Get("email").String())
+	_, err = time.Parse("2006-01-02", result.Get("birthday").String())
+	require.NoError(t, err)
+	require.Regexp(t, `^1[3-9]\d{9}$`, result.Get("mobile").String())
+	require.Regexp(t, `^https://`, result.Get("homepage").String())
+	nickname := result.Get("nickname").String()
+	require.True(t, len(nickname) >= 2 && len(nickname) <= 4, nickname)
+	age := result.Get("age").Int()
+	require.True(t, age >= 10 && age <= 20, age)
+	require.True(t, regexp.MustCompile(`^[A-Z]{2}-\d{4}$`).MatchString(result.Get("code").String()))
+	require.Len(t, result.Get("tags").Array(), 2)
+	require.True(t, result.Get("items.0.price").Exists())
+
+	again, err := apidocbuilder.NewMockGenerator(42).ParametersJson(ps)
+	require.NoError(t, err)
+	require.Equal(t, s, again)
+}
+
+func TestApiMockExample(t *testing.T) {
+	api := openAPIService.Apis[0]
+	api.Examples = nil
+	example, err := api.MockExample(apidocbuilder.MockSeedDefault)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), gjson.Get(example.RequestBody, "pageSize").Int())
+	require.Regexp(t, `@`, gjson.Get(example.Response, "data.items.0.email").String())
+	require.Equal(t, example.Response, api.GetFirstExample().Response)
+}
+
+func TestMockGeneratorPatternLength(t *testing.T) {
+	ps := apidocbuilder.Parameters{
+		{Fullname: "short", Type: "string", Schema: apidocbuilder.Schema{Pattern: `^[a-z]+$`, MaxLength: 2}},
+		{Fullname: "long", Type: "string", Schema: apidocbuilder.Schema{Pattern: `^\d{2,}$`, MinLength: 20, MaxLength: 24}},
+		{Fullname: "code", Type: "string", Schema: apidocbuilder.Schema{Pattern: `^(ab)*c$`, MinLength: 5, MaxLength: 5}},
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		s, err := apidocbuilder.NewMockGenerator(seed).ParametersJson(ps)
+		require.NoError(t, err)
+		require.Empty(t, ps.ValidateJson([]byte(s)), s)
+	}
+
+	impossible := apidocbuilder.Parameters{{Fullname: "code", Type: "string", Schema: apidocbuilder.Schema{Pattern: `^\d{4}$`, MaxLength: 2}}}
+	_, err := apidocbuilder.NewMockGenerator(1).ParametersJson(impossible)
+	require.ErrorIs(t, err, apidocbuilder.ERROR_MOCK_PATTERN_LENGTH)
+}
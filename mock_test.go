@@ -0,0 +1,83 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestMockHandler(t *testing.T) {
+	mockService := apidocbuilder.Service{
+		Apis: apidocbuilder.Apis{
+			{
+				Method: "GET", Path: "/user/{id}", ResponseContentType: "application/json",
+				Examples: apidocbuilder.Examples{
+					{Response: `{"name":"default"}`},
+					{Tag: "vip", Response: `{"name":"vip"}`},
+					{Tag: "404", Response: `{"code":404}`},
+				},
+			},
+			{
+				Method: "GET", Path: "/user/list",
+				ResponseBody: apidocbuilder.Parameters{{Fullname: "total", Type: "int", Example: "3"}},
+			},
+		},
+	}
+	handler := apidocbuilder.NewMockHandler(mockService)
+
+	cases := []struct {
+		name   string
+		target string
+		tag    string
+		status int
+		body   string
+	}{
+		{name: "default", target: "/user/1", status: 200, body: `{"name":"default"}`},
+		{name: "header tag", target: "/user/1", tag: "vip", status: 200, body: `{"name":"vip"}`},
+		{name: "query tag", target: "/user/1?_mockTag=vip", status: 200, body: `{"name":"vip"}`},
+		{name: "unknown tag", target: "/user/1", tag: "other", status: 200, body: `{"name":"default"}`},
+		{name: "status tag", target: "/user/1", tag: "404", status: 404, body: `{"code":404}`},
+		{name: "static path first", target: "/user/list", status: 200, body: `{"total":3}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, c.target, nil)
+			if c.tag != "" {
+				r.Header.Set(apidocbuilder.MOCK_HEADER_TAG, c.tag)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, c.status, w.Code)
+			require.JSONEq(t, c.body, w.Body.String())
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		})
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/user/1", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/user/1", nil)
+	r.Header.Set("Origin", "https://web.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, "https://web.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestMockHandlerNilExample(t *testing.T) {
+	var mockService apidocbuilder.Service
+	err := json.Unmarshal([]byte(`{"apis":[{"method":"GET","path":"/user","examples":[null,{"tag":"vip","response":"{\"name\":\"vip\"}"}]},{"method":"GET","path":"/order","examples":[null],"responseBody":[{"fullname":"id","type":"int","example":"1"}]}]}`), &mockService)
+	require.NoError(t, err)
+	handler := apidocbuilder.NewMockHandler(mockService)
+	for target, body := range map[string]string{"/user": `{"name":"vip"}`, "/order": `{"id":1}`} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, w.Code, target)
+		require.JSONEq(t, body, w.Body.String(), target)
+	}
+}
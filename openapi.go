@@ -0,0 +1,944 @@
+package apidocbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OpenAPI_Version = "3.1.0"
+
+	OpenAPI_Type_String  = "string"
+	OpenAPI_Type_Integer = "integer"
+	OpenAPI_Type_Number  = "number"
+	OpenAPI_Type_Boolean = "boolean"
+	OpenAPI_Type_Array   = "array"
+	OpenAPI_Type_Object  = "object"
+	OpenAPI_Type_Null    = "null"
+
+	OpenAPI_In_Query  = "query"
+	OpenAPI_In_Header = "header"
+	OpenAPI_In_Path   = "path"
+	OpenAPI_In_Cookie = "cookie"
+)
+
+// OpenAPI openapi 3.x 文档,只包含apidocbuilder 能表达的部分
+type OpenAPI struct {
+	OpenAPI    string                      `json:"openapi"`
+	Info       OpenAPIInfo                 `json:"info"`
+	Servers    []OpenAPIServer             `json:"servers,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths"`
+	Components *OpenAPIComponents          `json:"components,omitempty"`
+	Security   []map[string][]string       `json:"security,omitempty"`
+	Tags       []OpenAPITag                `json:"tags,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Version     string           `json:"version"`
+	Contact     *OpenAPIContact  `json:"contact,omitempty"`
+	License     *OpenAPILicense  `json:"license,omitempty"`
+	XContacts   []OpenAPIContact `json:"x-contacts,omitempty"` // openapi 只支持一个联系人,多个联系人放到扩展字段
+}
+
+type OpenAPIContact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+	XTel  string `json:"x-phone,omitempty"`
+}
+
+type OpenAPILicense struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type OpenAPIServer struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	XName       string `json:"x-name,omitempty"`
+	XProxy      string `json:"x-proxy,omitempty"`
+	XEnv        string `json:"x-environment,omitempty"`
+	XDefault    bool   `json:"x-default,omitempty"`
+}
+
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Ref             string         `json:"$ref,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	In              string         `json:"in,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	Required        bool           `json:"required,omitempty"`
+	Deprecated      bool           `json:"deprecated,omitempty"`
+	AllowEmptyValue bool           `json:"allowEmptyValue,omitempty"`
+	Style           string         `json:"style,omitempty"`
+	Explode         *bool          `json:"explode,omitempty"`
+	AllowReserved   bool           `json:"allowReserved,omitempty"`
+	Schema          *OpenAPISchema `json:"schema,omitempty"`
+	Example         any            `json:"example,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema             `json:"schema,omitempty"`
+	Example  any                        `json:"example,omitempty"`
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Ref         string `json:"$ref,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+	Value       any    `json:"value,omitempty"`
+}
+
+type OpenAPIResponse struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description"`
+	Headers     map[string]*OpenAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIHeader struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+	Example     any            `json:"example,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema         `json:"schemas,omitempty"`
+	Parameters      map[string]*OpenAPIParameter      `json:"parameters,omitempty"`
+	RequestBodies   map[string]*OpenAPIRequestBody    `json:"requestBodies,omitempty"`
+	Responses       map[string]*OpenAPIResponse       `json:"responses,omitempty"`
+	Headers         map[string]*OpenAPIHeader         `json:"headers,omitempty"`
+	Examples        map[string]*OpenAPIExample        `json:"examples,omitempty"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// OpenAPISchema json schema 子集,type 在 3.1 中可以是数组,解析时兼容
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 OpenAPISchemaType         `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Title                string                    `json:"title,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	XEnumNames           []string                  `json:"x-enumNames,omitempty"`
+	Default              any                       `json:"default,omitempty"`
+	Example              any                       `json:"example,omitempty"`
+	Examples             []any                     `json:"examples,omitempty"`
+	Deprecated           bool                      `json:"deprecated,omitempty"`
+	ReadOnly             bool                      `json:"readOnly,omitempty"`
+	WriteOnly            bool                      `json:"writeOnly,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"` // 3.0 字段,导入时兼容
+	MultipleOf           *float64                  `json:"multipleOf,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMaximum     any                       `json:"exclusiveMaximum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	ExclusiveMinimum     any                       `json:"exclusiveMinimum,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	UniqueItems          bool                      `json:"uniqueItems,omitempty"`
+	MaxProperties        *int                      `json:"maxProperties,omitempty"`
+	MinProperties        *int                      `json:"minProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties any                       `json:"additionalProperties,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+	OneOf                []*OpenAPISchema          `json:"oneOf,omitempty"`
+	AnyOf                []*OpenAPISchema          `json:"anyOf,omitempty"`
+}
+
+// OpenAPISchemaType 兼容 "type":"string" 和 "type":["string","null"] 两种写法
+type OpenAPISchemaType []string
+
+func (t OpenAPISchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *OpenAPISchemaType) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = OpenAPISchemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = OpenAPISchemaType(many)
+	return nil
+}
+
+// Main 返回第一个非null类型
+func (t OpenAPISchemaType) Main() (typ string) {
+	for _, v := range t {
+		if v != OpenAPI_Type_Null {
+			return v
+		}
+	}
+	return ""
+}
+
+func (t OpenAPISchemaType) Has(typ string) bool {
+	for _, v := range t {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OpenAPISchema) setType(typ string) {
+	if typ == "" {
+		s.Type = nil
+		return
+	}
+	s.Type = OpenAPISchemaType{typ}
+}
+
+// Service2OpenAPI 将服务转换为 openapi 3.1 文档
+func Service2OpenAPI(service Service) (doc *OpenAPI, err error) {
+	doc = &OpenAPI{
+		OpenAPI: OpenAPI_Version,
+		Info: OpenAPIInfo{
+			Title:       service.TitleOrDescription(),
+			Description: service.Description,
+			Version:     service.Version,
+		},
+		Paths: make(map[string]*OpenAPIPathItem),
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = service.Name
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1.0.0" // openapi 中 version 必填
+	}
+	for i, contact := range service.Contacts {
+		oaContact := OpenAPIContact{Name: contact.Name, Email: contact.Email, XTel: contact.Phone}
+		if i == 0 {
+			doc.Info.Contact = &oaContact
+			continue
+		}
+		doc.Info.XContacts = append(doc.Info.XContacts, oaContact)
+	}
+	if service.License != "" {
+		doc.Info.License = &OpenAPILicense{Name: service.License}
+		if strings.HasPrefix(service.License, "http") {
+			doc.Info.License.URL = service.License
+		}
+	}
+	for _, server := range service.Servers {
+		description := server.Description
+		if description == "" {
+			description = server.Title
+		}
+		doc.Servers = append(doc.Servers, OpenAPIServer{
+			URL:         server.URL,
+			Description: description,
+			XName:       server.Name,
+			XProxy:      server.Proxy,
+			XEnv:        server.Environment,
+			XDefault:    server.Default,
+		})
+	}
+	securitySchemes, security, err := service.openAPISecurity()
+	if err != nil {
+		return nil, err
+	}
+	if len(securitySchemes) > 0 {
+		doc.Components = &OpenAPIComponents{SecuritySchemes: securitySchemes}
+		doc.Security = security
+	}
+
+	for _, group := range service.Apis.GetGroups() {
+		if group == "" {
+			continue
+		}
+		doc.Tags = append(doc.Tags, OpenAPITag{Name: group})
+	}
+
+	for _, api := range service.Apis {
+		path := openAPIPath(api.Path)
+		method := strings.ToLower(api.Method)
+		if method == "" {
+			method = "get"
+		}
+		pathItem, ok := doc.Paths[path]
+		if !ok {
+			pathItem = &OpenAPIPathItem{}
+			doc.Paths[path] = pathItem
+		}
+		if _, exists := (*pathItem)[method]; exists {
+			err = fmt.Errorf("openapi: duplicate operation %s %s", strings.ToUpper(method), path)
+			return nil, err
+		}
+		(*pathItem)[method] = Api2OpenAPIOperation(api)
+	}
+	return doc, nil
+}
+
+// Service2OpenAPIJson 导出 openapi json 格式文档
+func Service2OpenAPIJson(service Service) (out []byte, err error) {
+	doc, err := Service2OpenAPI(service)
+	if err != nil {
+		return nil, err
+	}
+	out, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Service2OpenAPIYaml 导出 openapi yaml 格式文档
+func Service2OpenAPIYaml(service Service) (out []byte, err error) {
+	b, err := Service2OpenAPIJson(service)
+	if err != nil {
+		return nil, err
+	}
+	out, err = json2Yaml(b)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Api2OpenAPIOperation 将单个接口转换为 openapi operation
+func Api2OpenAPIOperation(api Api) (operation *OpenAPIOperation) {
+	summary := api.Title
+	if summary == "" {
+		summary = api.Summary
+	}
+	operation = &OpenAPIOperation{
+		OperationID: api.Name,
+		Summary:     summary,
+		Description: api.Description,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if api.Group != "" {
+		operation.Tags = []string{api.Group}
+	}
+
+	pathParams := make(map[string]bool)
+	for _, p := range api.Query {
+		in := openAPIParameterIn(p.Position, OpenAPI_In_Query)
+		if in == OpenAPI_In_Path {
+			pathParams[p.Name] = true
+		}
+		operation.Parameters = append(operation.Parameters, parameter2OpenAPIParameter(p, in))
+	}
+	for _, p := range api.RequestHeader {
+		if strings.EqualFold(p.Name, HEADER_NAME_CONTENT_TYPE) { // openapi 规定 content-type 头由 content 描述
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, parameter2OpenAPIParameter(p, OpenAPI_In_Header))
+	}
+	for _, name := range pathParamNames(api.Path) { // 路径中声明但未定义的参数,自动补全
+		if pathParams[name] {
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, &OpenAPIParameter{
+			Name:     name,
+			In:       OpenAPI_In_Path,
+			Required: true,
+			Schema:   &OpenAPISchema{Type: OpenAPISchemaType{OpenAPI_Type_String}},
+		})
+	}
+
+	if len(api.RequestBody) > 0 {
+		contentType := api.RequestContentType
+		if contentType == "" {
+			contentType = Header_Value_Content_Type_Json
+		}
+		required := false
+		for _, p := range api.RequestBody {
+			if p.Required {
+				required = true
+				break
+			}
+		}
+		mediaType := &OpenAPIMediaType{Schema: api.RequestBody.OpenAPISchema()}
+		for i, example := range api.Examples {
+			if example == nil || example.RequestBody == "" {
+				continue
+			}
+			if mediaType.Examples == nil {
+				mediaType.Examples = make(map[string]*OpenAPIExample)
+			}
+			mediaType.Examples[openAPIExampleKey(*example, i)] = &OpenAPIExample{
+				Summary: example.Title,
+				Value:   parseJsonValue(example.RequestBody),
+			}
+		}
+		operation.RequestBody = &OpenAPIRequestBody{
+			Required: required,
+			Content:  map[string]*OpenAPIMediaType{contentType: mediaType},
+		}
+	}
+
+	response := &OpenAPIResponse{Description: "OK"}
+	for _, p := range api.ResponseHeader {
+		if strings.EqualFold(p.Name, HEADER_NAME_CONTENT_TYPE) {
+			continue
+		}
+		if response.Headers == nil {
+			response.Headers = make(map[string]*OpenAPIHeader)
+		}
+		response.Headers[p.Name] = &OpenAPIHeader{
+			Description: p.TitleOrDescription(),
+			Required:    p.Required,
+			Schema:      parameter2OpenAPISchema(p),
+			Example:     openAPIValue(p.Example, p.Type),
+		}
+	}
+	if len(api.ResponseBody) > 0 {
+		contentType := api.ResponseContentType
+		if contentType == "" {
+			contentType = Header_Value_Content_Type_Json
+		}
+		mediaType := &OpenAPIMediaType{Schema: api.ResponseBody.OpenAPISchema()}
+		for i, example := range api.Examples {
+			if example == nil || example.Response == "" {
+				continue
+			}
+			if mediaType.Examples == nil {
+				mediaType.Examples = make(map[string]*OpenAPIExample)
+			}
+			mediaType.Examples[openAPIExampleKey(*example, i)] = &OpenAPIExample{
+				Summary: example.Title,
+				Value:   parseJsonValue(example.Response),
+			}
+		}
+		response.Content = map[string]*OpenAPIMediaType{contentType: mediaType}
+	}
+	operation.Responses["200"] = response
+	return operation
+}
+
+// OpenAPISchema 将扁平的参数列表(如 items[].name)转换为嵌套的 json schema
+func (ps Parameters) OpenAPISchema() (schema *OpenAPISchema) {
+	root := &OpenAPISchema{}
+	for _, p := range ps {
+		p.FormatField()
+		segments := parseFullname(p.Fullname)
+		var parent *OpenAPISchema
+		cur := root
+		name := ""
+		for _, seg := range segments {
+			if seg.Name != "" {
+				if !cur.Type.Has(OpenAPI_Type_Object) {
+					cur.setType(OpenAPI_Type_Object)
+				}
+				if cur.Properties == nil {
+					cur.Properties = make(map[string]*OpenAPISchema)
+				}
+				child, ok := cur.Properties[seg.Name]
+				if !ok {
+					child = &OpenAPISchema{}
+					cur.Properties[seg.Name] = child
+				}
+				parent, name, cur = cur, seg.Name, child
+			}
+			for i := 0; i < seg.ArrayDepth; i++ {
+				cur.setType(OpenAPI_Type_Array)
+				if cur.Items == nil {
+					cur.Items = &OpenAPISchema{}
+				}
+				cur = cur.Items
+			}
+		}
+		leaf := parameter2OpenAPISchema(p)
+		cur.merge(*leaf)
+		if p.Required && parent != nil && name != "" && !containsString(parent.Required, name) {
+			parent.Required = append(parent.Required, name)
+		}
+	}
+	if root.Type == nil {
+		root.setType(OpenAPI_Type_Object)
+	}
+	return root
+}
+
+// merge 合并叶子节点属性,已有子结构(properties/items)时保留结构类型
+func (s *OpenAPISchema) merge(leaf OpenAPISchema) {
+	structural := len(s.Properties) > 0 || s.Items != nil
+	if !structural || leaf.Type.Main() == s.Type.Main() {
+		if leaf.Type != nil {
+			s.Type = leaf.Type
+		}
+		if leaf.Format != "" {
+			s.Format = leaf.Format
+		}
+	}
+	if leaf.Title != "" {
+		s.Title = leaf.Title
+	}
+	if leaf.Description != "" {
+		s.Description = leaf.Description
+	}
+	if len(leaf.Enum) > 0 {
+		s.Enum = leaf.Enum
+		s.XEnumNames = leaf.XEnumNames
+	}
+	if leaf.Default != nil {
+		s.Default = leaf.Default
+	}
+	if leaf.Example != nil && !structural {
+		s.Example = leaf.Example
+	}
+	s.Deprecated = s.Deprecated || leaf.Deprecated
+	s.ReadOnly = s.ReadOnly || leaf.ReadOnly
+	s.WriteOnly = s.WriteOnly || leaf.WriteOnly
+	if leaf.MultipleOf != nil {
+		s.MultipleOf = leaf.MultipleOf
+	}
+	if leaf.Maximum != nil {
+		s.Maximum = leaf.Maximum
+		s.ExclusiveMaximum = leaf.ExclusiveMaximum
+	}
+	if leaf.Minimum != nil {
+		s.Minimum = leaf.Minimum
+		s.ExclusiveMinimum = leaf.ExclusiveMinimum
+	}
+	if leaf.MaxLength != nil {
+		s.MaxLength = leaf.MaxLength
+	}
+	if leaf.MinLength != nil {
+		s.MinLength = leaf.MinLength
+	}
+	if leaf.Pattern != "" {
+		s.Pattern = leaf.Pattern
+	}
+	if leaf.MaxItems != nil {
+		s.MaxItems = leaf.MaxItems
+	}
+	if leaf.MinItems != nil {
+		s.MinItems = leaf.MinItems
+	}
+	s.UniqueItems = s.UniqueItems || leaf.UniqueItems
+	if leaf.MaxProperties != nil {
+		s.MaxProperties = leaf.MaxProperties
+	}
+	if leaf.MinProperties != nil {
+		s.MinProperties = leaf.MinProperties
+	}
+}
+
+func parameter2OpenAPIParameter(p Parameter, in string) (oaParameter *OpenAPIParameter) {
+	p.FormatField()
+	description := p.Description
+	if description == "" {
+		description = p.Title
+	}
+	oaParameter = &OpenAPIParameter{
+		Name:            p.Name,
+		In:              in,
+		Description:     description,
+		Required:        p.Required || in == OpenAPI_In_Path, // path 参数必须为 required
+		Deprecated:      cast.ToBool(p.Deprecated),
+		AllowEmptyValue: p.AllowEmptyValue,
+		Style:           p.Serialize,
+		AllowReserved:   cast.ToBool(p.AllowReserved),
+		Schema:          parameter2OpenAPISchema(p),
+		Example:         openAPIValue(p.Example, p.Type),
+	}
+	if oaParameter.Name == "" {
+		oaParameter.Name = p.Fullname
+	}
+	if p.Explode != "" {
+		explode := cast.ToBool(p.Explode)
+		oaParameter.Explode = &explode
+	}
+	return oaParameter
+}
+
+func parameter2OpenAPISchema(p Parameter) (schema *OpenAPISchema) {
+	typ, format := openAPIType(p.Type)
+	schema = &OpenAPISchema{
+		Title:       p.Title,
+		Description: p.Description,
+		Deprecated:  cast.ToBool(p.Deprecated) || p.Schema.Deprecated,
+		ReadOnly:    p.Schema.ReadOnly,
+		WriteOnly:   p.Schema.WriteOnly,
+		Pattern:     p.Schema.Pattern,
+		UniqueItems: p.Schema.UniqueItems,
+	}
+	if schema.Title == "" {
+		schema.Title = p.Schema.Title
+	}
+	if schema.Description == "" {
+		schema.Description = p.Schema.Description
+	}
+	if schema.Description == schema.Title {
+		schema.Description = ""
+	}
+	schema.setType(typ)
+	if f := openAPIFormat(p.GetFormat()); f != "" {
+		format = f
+	}
+	schema.Format = format
+	if schema.Pattern == "" {
+		schema.Pattern = p.RegExp
+	}
+
+	enum := p.Enum
+	if enum == "" {
+		enum = p.Schema.Enum
+	}
+	enumNames := p.EnumNames
+	if enumNames == "" {
+		enumNames = p.Schema.EnumNames
+	}
+	if enum != "" {
+		for _, v := range splitEnum(enum) {
+			schema.Enum = append(schema.Enum, openAPIValue(v, typ))
+		}
+		if enumNames != "" {
+			schema.XEnumNames = splitEnum(enumNames)
+		}
+	}
+	defaultValue := p.Default
+	if defaultValue == "" {
+		defaultValue = p.Schema.Default
+	}
+	schema.Default = openAPIValue(defaultValue, typ)
+	example := p.Example
+	if example == "" {
+		example = p.Schema.Example
+	}
+	schema.Example = openAPIValue(example, typ)
+
+	if p.Schema.MultipleOf > 0 {
+		multipleOf := float64(p.Schema.MultipleOf)
+		schema.MultipleOf = &multipleOf
+	}
+	if p.Schema.Maximum != 0 { // 0 为未设置
+		maximum := float64(p.Schema.Maximum)
+		schema.Maximum = &maximum
+		if p.Schema.ExclusiveMaximum {
+			schema.Maximum, schema.ExclusiveMaximum = nil, maximum // 3.1 中 exclusiveMaximum 为数值
+		}
+	}
+	if p.Schema.Minimum != nil {
+		minimum := float64(*p.Schema.Minimum)
+		schema.Minimum = &minimum
+		if p.Schema.ExclusiveMinimum {
+			schema.Minimum, schema.ExclusiveMinimum = nil, minimum
+		}
+	}
+	if p.Schema.MaxLength > 0 {
+		schema.MaxLength = intPtr(p.Schema.MaxLength)
+	}
+	if p.Schema.MinLength > 0 {
+		schema.MinLength = intPtr(p.Schema.MinLength)
+	}
+	if p.Schema.MaxItems > 0 {
+		schema.MaxItems = intPtr(p.Schema.MaxItems)
+	}
+	if p.Schema.MinItems > 0 {
+		schema.MinItems = intPtr(p.Schema.MinItems)
+	}
+	if p.Schema.MaxProperties > 0 {
+		schema.MaxProperties = intPtr(p.Schema.MaxProperties)
+	}
+	if p.Schema.MinProperties > 0 {
+		schema.MinProperties = intPtr(p.Schema.MinProperties)
+	}
+	return schema
+}
+
+// openAPIType 将参数类型(含go类型名称)转换为 openapi 类型和格式
+func openAPIType(typ string) (oaType string, format string) {
+	switch strings.ToLower(typ) {
+	case "string", "str", "text":
+		return OpenAPI_Type_String, ""
+	case "int", "integer", "uint", "int8", "int16", "uint8", "uint16":
+		return OpenAPI_Type_Integer, ""
+	case "int32", "uint32":
+		return OpenAPI_Type_Integer, "int32"
+	case "int64", "uint64":
+		return OpenAPI_Type_Integer, "int64"
+	case "number", "float", "decimal":
+		return OpenAPI_Type_Number, ""
+	case "float32":
+		return OpenAPI_Type_Number, "float"
+	case "float64", "double":
+		return OpenAPI_Type_Number, "double"
+	case "bool", "boolean":
+		return OpenAPI_Type_Boolean, ""
+	case "array", "slice":
+		return OpenAPI_Type_Array, ""
+	case "object", "struct", "map":
+		return OpenAPI_Type_Object, ""
+	case "null":
+		return OpenAPI_Type_Null, ""
+	case "", "any", "interface":
+		return "", ""
+	}
+	return OpenAPI_Type_String, ""
+}
+
+var openAPIFormatAlias = map[string]string{
+	"datetime": "date-time",
+	"url":      "uri",
+	"tel":      "phone",
+}
+
+// openAPIFormat 从格式列表中取第一个非类型名称的格式
+func openAPIFormat(formats Format) (format string) {
+	for _, f := range formats {
+		lower := strings.ToLower(f)
+		if isTypeName(lower) {
+			continue // 类型名称记录在format中(参见 Parameter.Merge),不作为格式输出
+		}
+		if alias, ok := openAPIFormatAlias[lower]; ok {
+			return alias
+		}
+		return f
+	}
+	return ""
+}
+
+func isTypeName(typ string) bool {
+	switch typ {
+	case "string", "str", "text", "any", "interface", "null":
+		return true
+	}
+	oaType, _ := openAPIType(typ)
+	return oaType != OpenAPI_Type_String
+}
+
+// openAPIValue 按类型转换字符串值,转换失败保留原字符串
+func openAPIValue(value string, oaType string) any {
+	if value == "" {
+		return nil
+	}
+	switch oaType {
+	case OpenAPI_Type_Integer:
+		if v, err := cast.ToInt64E(value); err == nil {
+			return v
+		}
+	case OpenAPI_Type_Number:
+		if v, err := cast.ToFloat64E(value); err == nil {
+			return v
+		}
+	case OpenAPI_Type_Boolean:
+		if v, err := cast.ToBoolE(value); err == nil {
+			return v
+		}
+	case OpenAPI_Type_Array, OpenAPI_Type_Object, "":
+		return parseJsonValue(value)
+	}
+	return value
+}
+
+func openAPIParameterIn(position string, defaultIn string) (in string) {
+	switch strings.ToLower(position) {
+	case "query":
+		return OpenAPI_In_Query
+	case "head", "header":
+		return OpenAPI_In_Header
+	case "path":
+		return OpenAPI_In_Path
+	case "cookie":
+		return OpenAPI_In_Cookie
+	}
+	return defaultIn
+}
+
+func openAPIExampleKey(example Example, index int) (key string) {
+	if example.Tag != "" {
+		return example.Tag
+	}
+	return fmt.Sprintf("example%d", index+1)
+}
+
+// openAPISecurity 解析 Service.Security ,支持 securitySchemes json 或 bearer/basic/头部名称 简写
+func (s Service) openAPISecurity() (schemes map[string]*OpenAPISecurityScheme, requirements []map[string][]string, err error) {
+	security := strings.TrimSpace(s.Security)
+	if security == "" {
+		return nil, nil, nil
+	}
+	schemes = make(map[string]*OpenAPISecurityScheme)
+	if strings.HasPrefix(security, "{") {
+		err = json.Unmarshal([]byte(security), &schemes)
+		if err != nil {
+			err = fmt.Errorf("openapi: parse Service.Security: %w", err)
+			return nil, nil, err
+		}
+	} else {
+		switch strings.ToLower(security) {
+		case "bearer", "jwt":
+			schemes["bearerAuth"] = &OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}
+		case "basic":
+			schemes["basicAuth"] = &OpenAPISecurityScheme{Type: "http", Scheme: "basic"}
+		default:
+			schemes["apiKeyAuth"] = &OpenAPISecurityScheme{Type: "apiKey", In: OpenAPI_In_Header, Name: security}
+		}
+	}
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		requirements = append(requirements, map[string][]string{name: {}})
+	}
+	return schemes, requirements, nil
+}
+
+var colonPathParamReg = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+var bracePathParamReg = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// openAPIPath 将 /user/:id 风格转换为 /user/{id}
+func openAPIPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return colonPathParamReg.ReplaceAllString(path, "{$1}")
+}
+
+func pathParamNames(path string) (names []string) {
+	for _, match := range bracePathParamReg.FindAllStringSubmatch(openAPIPath(path), -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+type fullnameSegment struct {
+	Name       string
+	ArrayDepth int
+}
+
+// parseFullname 解析 data.items[].name 格式参数名称
+func parseFullname(fullname string) (segments []fullnameSegment) {
+	fullname = strings.ReplaceAll(fullname, ".[]", "[]")
+	for _, part := range strings.Split(fullname, ".") {
+		seg := fullnameSegment{Name: part}
+		for strings.HasSuffix(seg.Name, "[]") {
+			seg.Name = strings.TrimSuffix(seg.Name, "[]")
+			seg.ArrayDepth++
+		}
+		if seg.Name == "" && seg.ArrayDepth == 0 {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+func splitEnum(enum string) (values []string) {
+	for _, v := range strings.Split(enum, ",") {
+		values = append(values, strings.TrimSpace(v))
+	}
+	return values
+}
+
+// parseJsonValue 合法json返回解析后的值,否则返回原字符串
+func parseJsonValue(s string) any {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return nil
+	}
+	if json.Valid([]byte(trimmed)) {
+		var v any
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// json2Yaml 借助 yaml 兼容 json 的特性转换,同时保留json中key的顺序
+func json2Yaml(b []byte) (out []byte, err error) {
+	var node yaml.Node
+	err = yaml.Unmarshal(b, &node)
+	if err != nil {
+		return nil, err
+	}
+	clearYamlStyle(&node)
+	var w bytes.Buffer
+	enc := yaml.NewEncoder(&w)
+	enc.SetIndent(2)
+	err = enc.Encode(&node)
+	if err != nil {
+		return nil, err
+	}
+	out = w.Bytes()
+	return out, nil
+}
+
+// clearYamlStyle 去掉json的flow风格,"true" "123" 等字符串yaml编码时会自动加引号
+func clearYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYamlStyle(child)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func containsString(arr []string, s string) bool {
+	for _, v := range arr {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1006 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/suifengpiao14/funcs"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportWarning 导入时无法映射的内容,记录位置和原因,避免静默丢弃
+type ImportWarning struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (w ImportWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Location, w.Message)
+}
+
+type ImportWarnings []ImportWarning
+
+func (ws *ImportWarnings) Add(location string, format string, args ...any) {
+	*ws = append(*ws, ImportWarning{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ws ImportWarnings) String() string {
+	lines := make([]string, 0, len(ws))
+	for _, w := range ws {
+		lines = append(lines, w.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var ERROR_OPENAPI_VERSION = errors.New("unsupported openapi/swagger version")
+
+// openAPIMethods 固定顺序,保证导入结果稳定
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPI2Service 解析 openapi 3.x 或 swagger 2.0 文档(json/yaml),$ref 会被展开,嵌套schema 平铺为 Parameter.Fullname
+func OpenAPI2Service(data []byte) (service *Service, warnings ImportWarnings, err error) {
+	var raw any
+	err = yaml.Unmarshal(data, &raw) // yaml 兼容 json
+	if err != nil {
+		err = errors.WithMessage(err, "parse openapi document")
+		return nil, nil, err
+	}
+	root, ok := normalizeYamlValue(raw).(map[string]any)
+	if !ok {
+		err = errors.New("openapi document must be an object")
+		return nil, nil, err
+	}
+	resolver := &refResolver{root: root, warnings: &warnings}
+	root = resolver.resolve(root).(map[string]any)
+
+	var importer *openAPIImporter
+	switch {
+	case strings.HasPrefix(cast.ToString(root["openapi"]), "3."):
+		importer, err = newOpenAPI3Importer(root, &warnings)
+	case strings.HasPrefix(cast.ToString(root["swagger"]), "2."):
+		importer, err = newSwagger2Importer(root, &warnings)
+	default:
+		err = errors.WithMessagef(ERROR_OPENAPI_VERSION, "openapi:%v,swagger:%v", root["openapi"], root["swagger"])
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	service = importer.service()
+	return service, warnings, nil
+}
+
+type openAPIImportOperation struct {
+	Path           string
+	Method         string
+	PathParameters []*OpenAPIParameter
+	Operation      *OpenAPIOperation
+}
+
+type openAPIImporter struct {
+	info            OpenAPIInfo
+	servers         []OpenAPIServer
+	securitySchemes map[string]*OpenAPISecurityScheme
+	operations      []openAPIImportOperation
+	warnings        *ImportWarnings
+}
+
+type openAPI3Doc struct {
+	Info       OpenAPIInfo        `json:"info"`
+	Servers    []OpenAPIServer    `json:"servers"`
+	Components *OpenAPIComponents `json:"components"`
+}
+
+func newOpenAPI3Importer(root map[string]any, warnings *ImportWarnings) (importer *openAPIImporter, err error) {
+	var doc openAPI3Doc
+	err = convertByJson(root, &doc)
+	if err != nil {
+		return nil, err
+	}
+	importer = &openAPIImporter{info: doc.Info, servers: doc.Servers, warnings: warnings}
+	if doc.Components != nil {
+		importer.securitySchemes = doc.Components.SecuritySchemes
+	}
+	if _, ok := root["webhooks"]; ok {
+		warnings.Add("#/webhooks", "webhooks are not supported")
+	}
+	paths, _ := root["paths"].(map[string]any)
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := paths[path].(map[string]any)
+		var pathParameters []*OpenAPIParameter
+		if v, ok := pathItem["parameters"]; ok {
+			err = convertByJson(v, &pathParameters)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "path:%s parameters", path)
+			}
+		}
+		for key := range pathItem {
+			if key != "parameters" && key != "summary" && key != "description" && !containsString(openAPIMethods, key) {
+				warnings.Add(path, "path item field %q is not supported", key)
+			}
+		}
+		for _, method := range openAPIMethods {
+			v, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+			operation := &OpenAPIOperation{}
+			err = convertByJson(v, operation)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "%s %s", strings.ToUpper(method), path)
+			}
+			importer.operations = append(importer.operations, openAPIImportOperation{
+				Path:           path,
+				Method:         method,
+				PathParameters: pathParameters,
+				Operation:      operation,
+			})
+		}
+	}
+	return importer, nil
+}
+
+type swagger2Doc struct {
+	Info                OpenAPIInfo                       `json:"info"`
+	Host                string                            `json:"host"`
+	BasePath            string                            `json:"basePath"`
+	Schemes             []string                          `json:"schemes"`
+	Consumes            []string                          `json:"consumes"`
+	Produces            []string                          `json:"produces"`
+	SecurityDefinitions map[string]swagger2SecurityScheme `json:"securityDefinitions"`
+}
+
+type swagger2SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Name        string `json:"name"`
+	In          string `json:"in"`
+}
+
+type swagger2Operation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description"`
+	Tags        []string                    `json:"tags"`
+	Consumes    []string                    `json:"consumes"`
+	Produces    []string                    `json:"produces"`
+	Parameters  []swagger2Parameter         `json:"parameters"`
+	Responses   map[string]swagger2Response `json:"responses"`
+	Deprecated  bool                        `json:"deprecated"`
+}
+
+type swagger2Parameter struct {
+	OpenAPISchema
+	Name             string         `json:"name"`
+	In               string         `json:"in"`
+	Required         bool           `json:"required"`
+	AllowEmptyValue  bool           `json:"allowEmptyValue"`
+	CollectionFormat string         `json:"collectionFormat"`
+	Schema           *OpenAPISchema `json:"schema"`
+	XExample         any            `json:"x-example"`
+}
+
+type swagger2Response struct {
+	Description string                    `json:"description"`
+	Schema      *OpenAPISchema            `json:"schema"`
+	Headers     map[string]*OpenAPISchema `json:"headers"`
+	Examples    map[string]any            `json:"examples"`
+}
+
+// newSwagger2Importer 将 swagger 2.0 转换为 openapi 3 结构后统一导入
+func newSwagger2Importer(root map[string]any, warnings *ImportWarnings) (importer *openAPIImporter, err error) {
+	var doc swagger2Doc
+	err = convertByJson(root, &doc)
+	if err != nil {
+		return nil, err
+	}
+	importer = &openAPIImporter{info: doc.Info, warnings: warnings}
+	schemes := doc.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	if doc.Host != "" {
+		for _, scheme := range schemes {
+			importer.servers = append(importer.servers, OpenAPIServer{URL: fmt.Sprintf("%s://%s%s", scheme, doc.Host, doc.BasePath), XName: scheme})
+		}
+	} else if doc.BasePath != "" {
+		importer.servers = append(importer.servers, OpenAPIServer{URL: doc.BasePath})
+	}
+	if len(doc.SecurityDefinitions) > 0 {
+		importer.securitySchemes = make(map[string]*OpenAPISecurityScheme)
+		for name, def := range doc.SecurityDefinitions {
+			scheme := &OpenAPISecurityScheme{Type: def.Type, Description: def.Description, Name: def.Name, In: def.In}
+			switch def.Type {
+			case "basic":
+				scheme.Type, scheme.Scheme = "http", "basic"
+			case "oauth2":
+				warnings.Add("#/securityDefinitions/"+name, "oauth2 flows are not supported, only the scheme type is kept")
+			}
+			importer.securitySchemes[name] = scheme
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	for _, path := range sortedKeys(paths) {
+		pathItem, _ := paths[path].(map[string]any)
+		var pathParameters []swagger2Parameter
+		if v, ok := pathItem["parameters"]; ok {
+			err = convertByJson(v, &pathParameters)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "path:%s parameters", path)
+			}
+		}
+		for _, method := range openAPIMethods {
+			v, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+			var swaggerOperation swagger2Operation
+			err = convertByJson(v, &swaggerOperation)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "%s %s", strings.ToUpper(method), path)
+			}
+			location := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+			consumes := swaggerOperation.Consumes
+			if len(consumes) == 0 {
+				consumes = doc.Consumes
+			}
+			produces := swaggerOperation.Produces
+			if len(produces) == 0 {
+				produces = doc.Produces
+			}
+			operation := swagger2Operation2OpenAPI(swaggerOperation, pathParameters, consumes, produces, location, warnings)
+			importer.operations = append(importer.operations, openAPIImportOperation{Path: path, Method: method, Operation: operation})
+		}
+	}
+	return importer, nil
+}
+
+func swagger2Operation2OpenAPI(op swagger2Operation, pathParameters []swagger2Parameter, consumes []string, produces []string, location string, warnings *ImportWarnings) (operation *OpenAPIOperation) {
+	operation = &OpenAPIOperation{
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if len(consumes) == 0 {
+		consumes = []string{Header_Value_Content_Type_Json}
+	}
+	if len(produces) == 0 {
+		produces = []string{Header_Value_Content_Type_Json}
+	}
+	parameters := make([]swagger2Parameter, 0, len(pathParameters)+len(op.Parameters))
+	for _, p := range pathParameters {
+		overridden := false
+		for _, other := range op.Parameters {
+			if other.Name == p.Name && other.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			parameters = append(parameters, p)
+		}
+	}
+	parameters = append(parameters, op.Parameters...)
+
+	var formSchema *OpenAPISchema
+	for _, p := range parameters {
+		switch p.In {
+		case "body":
+			operation.RequestBody = &OpenAPIRequestBody{Description: p.Description, Required: p.Required, Content: make(map[string]*OpenAPIMediaType)}
+			for _, contentType := range consumes {
+				operation.RequestBody.Content[contentType] = &OpenAPIMediaType{Schema: p.Schema}
+			}
+		case "formData":
+			if formSchema == nil {
+				formSchema = &OpenAPISchema{Type: OpenAPISchemaType{OpenAPI_Type_Object}, Properties: make(map[string]*OpenAPISchema)}
+			}
+			schema := p.OpenAPISchema
+			formSchema.Properties[p.Name] = &schema
+			if p.Required {
+				formSchema.Required = append(formSchema.Required, p.Name)
+			}
+		default:
+			schema := p.OpenAPISchema
+			parameter := &OpenAPIParameter{
+				Name:            p.Name,
+				In:              p.In,
+				Description:     schema.Description,
+				Required:        p.Required,
+				AllowEmptyValue: p.AllowEmptyValue,
+				Schema:          &schema,
+				Example:         p.XExample,
+			}
+			schema.Description = ""
+			switch p.CollectionFormat {
+			case "", "csv":
+			case "multi":
+				explode := true
+				parameter.Style, parameter.Explode = "form", &explode
+			case "ssv":
+				parameter.Style = "spaceDelimited"
+			case "pipes":
+				parameter.Style = "pipeDelimited"
+			default:
+				warnings.Add(location, "collectionFormat %q of parameter %s is not supported", p.CollectionFormat, p.Name)
+			}
+			if p.CollectionFormat == "csv" || (p.CollectionFormat == "" && schema.Type.Has(OpenAPI_Type_Array)) {
+				explode := false
+				parameter.Style, parameter.Explode = "form", &explode
+			}
+			operation.Parameters = append(operation.Parameters, parameter)
+		}
+	}
+	if formSchema != nil {
+		contentType := "application/x-www-form-urlencoded"
+		for _, c := range consumes {
+			if strings.HasPrefix(c, "multipart/") {
+				contentType = c
+			}
+		}
+		operation.RequestBody = &OpenAPIRequestBody{Content: map[string]*OpenAPIMediaType{contentType: {Schema: formSchema}}}
+	}
+
+	for code, resp := range op.Responses {
+		response := &OpenAPIResponse{Description: resp.Description}
+		for name, header := range resp.Headers {
+			if response.Headers == nil {
+				response.Headers = make(map[string]*OpenAPIHeader)
+			}
+			response.Headers[name] = &OpenAPIHeader{Description: header.Description, Schema: header}
+		}
+		if resp.Schema != nil || len(resp.Examples) > 0 {
+			response.Content = make(map[string]*OpenAPIMediaType)
+			for _, contentType := range produces {
+				response.Content[contentType] = &OpenAPIMediaType{Schema: resp.Schema, Example: resp.Examples[contentType]}
+			}
+			for contentType, example := range resp.Examples {
+				if _, ok := response.Content[contentType]; !ok {
+					response.Content[contentType] = &OpenAPIMediaType{Schema: resp.Schema, Example: example}
+				}
+			}
+		}
+		operation.Responses[code] = response
+	}
+	return operation
+}
+
+func (importer *openAPIImporter) service() (service *Service) {
+	service = &Service{
+		Name:        importer.info.Title,
+		Title:       importer.info.Title,
+		Description: importer.info.Description,
+		Version:     importer.info.Version,
+	}
+	if importer.info.License != nil {
+		service.License = importer.info.License.Name
+		if service.License == "" {
+			service.License = importer.info.License.URL
+		}
+	}
+	contacts := importer.info.XContacts
+	if importer.info.Contact != nil {
+		contacts = append([]OpenAPIContact{*importer.info.Contact}, contacts...)
+	}
+	for _, contact := range contacts {
+		service.AddConcat(Contact{Name: contact.Name, Email: contact.Email, Phone: contact.XTel})
+	}
+	service.Servers = make(Servers, 0, len(importer.servers))
+	for i, oaServer := range importer.servers {
+		server := Server{
+			Name:        oaServer.XName,
+			URL:         oaServer.URL,
+			Description: oaServer.Description,
+			Proxy:       oaServer.XProxy,
+			Environment: oaServer.XEnv,
+			Default:     oaServer.XDefault,
+		}
+		if server.Name == "" {
+			server.Name = fmt.Sprintf("server%d", i+1)
+		}
+		server.Title = makeTitle(server.Description)
+		service.Servers = append(service.Servers, server) // 导入时保留文档中的所有服务器,不按名称去重
+	}
+	if len(importer.securitySchemes) > 0 {
+		b, err := json.Marshal(importer.securitySchemes)
+		if err == nil {
+			service.Security = string(b)
+		}
+	}
+
+	apis := make(Apis, 0, len(importer.operations))
+	for _, operation := range importer.operations {
+		apis = append(apis, importer.api(operation))
+	}
+	service.AddApi(apis...)
+	return service
+}
+
+var openAPIGeneratedExampleKeyReg = regexp.MustCompile(`^example\d+$`)
+
+func (importer *openAPIImporter) api(importOperation openAPIImportOperation) (api Api) {
+	op := importOperation.Operation
+	location := fmt.Sprintf("%s %s", strings.ToUpper(importOperation.Method), importOperation.Path)
+	api = Api{
+		Name:        op.OperationID,
+		Title:       op.Summary,
+		Description: op.Description,
+		Method:      strings.ToUpper(importOperation.Method),
+		Path:        importOperation.Path,
+	}
+	if api.Name == "" {
+		api.Name = makeApiName(importOperation.Method, api.Path)
+	}
+	if len(op.Tags) > 0 {
+		api.Group = op.Tags[0]
+		if len(op.Tags) > 1 {
+			importer.warnings.Add(location, "only the first tag %q is used as group, ignored:%s", op.Tags[0], strings.Join(op.Tags[1:], ","))
+		}
+	}
+	if op.Deprecated {
+		importer.warnings.Add(location, "deprecated flag of operation is not supported")
+	}
+	if len(op.Security) > 0 {
+		importer.warnings.Add(location, "operation level security is not supported")
+	}
+
+	parameters := make([]*OpenAPIParameter, 0)
+	for _, p := range importOperation.PathParameters {
+		overridden := false
+		for _, other := range op.Parameters {
+			if other.Name == p.Name && other.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			parameters = append(parameters, p)
+		}
+	}
+	parameters = append(parameters, op.Parameters...)
+	for _, oaParameter := range parameters {
+		params := openAPIParameter2Parameters(*oaParameter, location, importer.warnings)
+		switch oaParameter.In {
+		case OpenAPI_In_Header:
+			api.RequestHeader.Add(params...)
+		case OpenAPI_In_Query, OpenAPI_In_Path, OpenAPI_In_Cookie:
+			api.Query.Add(params...)
+		default:
+			importer.warnings.Add(location, "parameter %s in %q is not supported", oaParameter.Name, oaParameter.In)
+		}
+	}
+
+	requestExamples := make(map[string]*OpenAPIExample)
+	if op.RequestBody != nil {
+		contentType, mediaType := selectOpenAPIMediaType(op.RequestBody.Content)
+		if len(op.RequestBody.Content) > 1 {
+			importer.warnings.Add(location, "request body has %d content types, only %s is imported", len(op.RequestBody.Content), contentType)
+		}
+		if mediaType != nil {
+			api.RequestContentType = contentType
+			api.RequestBody = openAPISchema2Parameters(mediaType.Schema, "", op.RequestBody.Required, location+" requestBody", importer.warnings)
+			requestExamples = openAPIMediaTypeExamples(mediaType)
+		}
+	}
+
+	responseExamples := make(map[string]*OpenAPIExample)
+	code := selectOpenAPIResponseCode(op.Responses)
+	for _, other := range sortedKeys(op.Responses) {
+		if other != code {
+			importer.warnings.Add(location, "response %s is not imported, only %s is kept", other, code)
+		}
+	}
+	if response, ok := op.Responses[code]; ok && response != nil {
+		for _, name := range sortedKeys(response.Headers) {
+			header := response.Headers[name]
+			p := openAPISchema2Parameter(name, header.Schema, header.Required)
+			if p.Description == "" {
+				p.Description = header.Description
+			}
+			p.Position = PARAMETER_ATTR_POSITION_ENUM_HEADER
+			api.ResponseHeader.Add(p)
+		}
+		contentType, mediaType := selectOpenAPIMediaType(response.Content)
+		if len(response.Content) > 1 {
+			importer.warnings.Add(location, "response %s has %d content types, only %s is imported", code, len(response.Content), contentType)
+		}
+		if mediaType != nil {
+			api.ResponseContentType = contentType
+			api.ResponseBody = openAPISchema2Parameters(mediaType.Schema, "", false, fmt.Sprintf("%s response %s", location, code), importer.warnings)
+			responseExamples = openAPIMediaTypeExamples(mediaType)
+		}
+	}
+
+	keys := make([]string, 0)
+	for key := range requestExamples {
+		keys = append(keys, key)
+	}
+	for key := range responseExamples {
+		if _, ok := requestExamples[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		example := &Example{
+			Method:      api.Method,
+			Title:       key,
+			URL:         api.Path,
+			ContentType: api.RequestContentType,
+		}
+		if !openAPIGeneratedExampleKeyReg.MatchString(key) {
+			example.Tag = key
+		}
+		if api.RequestContentType != "" {
+			example.Headers = map[string]string{HEADER_NAME_CONTENT_TYPE: api.RequestContentType}
+		}
+		if reqExample, ok := requestExamples[key]; ok {
+			if reqExample.Summary != "" {
+				example.Title = reqExample.Summary
+			}
+			example.RequestBody = openAPIExampleBody(reqExample.Value)
+		}
+		if respExample, ok := responseExamples[key]; ok {
+			if respExample.Summary != "" {
+				example.Title = respExample.Summary
+			}
+			example.Response = openAPIExampleBody(respExample.Value)
+		}
+		api.Examples = append(api.Examples, example)
+	}
+	return api
+}
+
+// makeApiName 导入的接口没有名称时,根据方法和路径生成(Api.Init 只用路径,同路径不同方法会重名)
+func makeApiName(method string, path string) (name string) {
+	path = strings.NewReplacer("{", "", "}", "", ":", "", "/", "_", "-", "_", ".", "_").Replace(strings.Trim(path, "/"))
+	name = funcs.ToLowerCamel(fmt.Sprintf("%s_%s", strings.ToLower(method), path))
+	return name
+}
+
+// openAPIParameter2Parameters 对象/数组参数(如 deepObject)平铺成多个参数
+func openAPIParameter2Parameters(oaParameter OpenAPIParameter, location string, warnings *ImportWarnings) (params Parameters) {
+	schema := oaParameter.Schema
+	if schema != nil && (len(schema.Properties) > 0 || (schema.Items != nil && len(schema.Items.Properties) > 0)) {
+		params = openAPISchema2Parameters(schema, oaParameter.Name, oaParameter.Required, fmt.Sprintf("%s parameter %s", location, oaParameter.Name), warnings)
+	} else {
+		params = Parameters{openAPISchema2Parameter(oaParameter.Name, schema, oaParameter.Required)}
+	}
+	for i := range params {
+		p := &params[i]
+		if p.Fullname == oaParameter.Name {
+			if oaParameter.Description != "" {
+				p.Description = oaParameter.Description
+				p.Schema.Description = oaParameter.Description
+			}
+			if oaParameter.Example != nil {
+				p.Example = openAPIExampleString(oaParameter.Example)
+			}
+			p.AllowEmptyValue = oaParameter.AllowEmptyValue
+			p.Serialize = oaParameter.Style
+			if oaParameter.Explode != nil {
+				p.Explode = cast.ToString(*oaParameter.Explode)
+			}
+			if oaParameter.AllowReserved {
+				p.AllowReserved = "true"
+			}
+			if oaParameter.Deprecated {
+				p.Deprecated = "true"
+			}
+		}
+		switch oaParameter.In {
+		case OpenAPI_In_Path, OpenAPI_In_Cookie:
+			p.Position = oaParameter.In
+		case OpenAPI_In_Header:
+			p.Position = PARAMETER_ATTR_POSITION_ENUM_HEADER
+		}
+	}
+	return params
+}
+
+// openAPISchema2Parameters 将嵌套schema 平铺为 Fullname 形式参数(data.items[].name),无法表示的结构记录到 warnings
+func openAPISchema2Parameters(schema *OpenAPISchema, prefix string, required bool, location string, warnings *ImportWarnings) (params Parameters) {
+	params = make(Parameters, 0)
+	if schema == nil {
+		return params
+	}
+	field := firstNotEmpty(prefix, "$")
+	schema = mergeOpenAPIAllOf(schema)
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 { // 多选一结构只取第一个
+		keyword, alternatives := "oneOf", schema.OneOf
+		if len(alternatives) == 0 {
+			keyword, alternatives = "anyOf", schema.AnyOf
+		}
+		if len(alternatives) > 1 {
+			ignored := make([]string, 0, len(alternatives)-1)
+			for i, alternative := range alternatives[1:] {
+				ignored = append(ignored, openAPISchemaLabel(fmt.Sprintf("%s[%d]", keyword, i+1), alternative))
+			}
+			warnings.Add(location, "schema %s: only the first %s alternative is imported, ignored:%s", field, keyword, strings.Join(ignored, ","))
+		}
+		first := *mergeOpenAPIAllOf(alternatives[0])
+		if first.Title == "" {
+			first.Title = schema.Title
+		}
+		if first.Description == "" {
+			first.Description = schema.Description
+		}
+		schema = &first
+	}
+	if additional, isBool := schema.AdditionalProperties.(bool); schema.AdditionalProperties != nil && (!isBool || additional) { // false 不丢失信息
+		warnings.Add(location, "schema %s: additionalProperties is not supported, only declared properties are imported", field)
+	}
+
+	switch {
+	case len(schema.Properties) > 0:
+		if prefix != "" && (schema.Title != "" || schema.Description != "") {
+			params = append(params, openAPISchema2Parameter(prefix, schema, required))
+		}
+		for _, name := range sortedKeys(schema.Properties) {
+			fullname := name
+			if prefix != "" {
+				fullname = fmt.Sprintf("%s.%s", prefix, name)
+			}
+			params = append(params, openAPISchema2Parameters(schema.Properties[name], fullname, containsString(schema.Required, name), location, warnings)...)
+		}
+	case schema.Items != nil:
+		if prefix != "" && (schema.Title != "" || schema.Description != "" || schema.MinItems != nil || schema.MaxItems != nil) {
+			params = append(params, openAPISchema2Parameter(prefix, schema, required))
+		}
+		params = append(params, openAPISchema2Parameters(schema.Items, fmt.Sprintf("%s[]", prefix), false, location, warnings)...)
+	default:
+		if prefix == "" { // 根节点为空对象
+			return params
+		}
+		params = append(params, openAPISchema2Parameter(prefix, schema, required))
+	}
+	params.FormatField()
+	return params
+}
+
+// openAPISchemaLabel 警告中说明被忽略的子schema,如 oneOf[1](card)
+func openAPISchemaLabel(name string, schema *OpenAPISchema) (label string) {
+	if schema == nil {
+		return name
+	}
+	if detail := firstNotEmpty(schema.Title, schema.Type.Main()); detail != "" {
+		return fmt.Sprintf("%s(%s)", name, detail)
+	}
+	return name
+}
+
+func openAPISchema2Parameter(fullname string, schema *OpenAPISchema, required bool) (p Parameter) {
+	if schema == nil {
+		schema = &OpenAPISchema{}
+	}
+	typ := schema.Type.Main()
+	if typ == "" {
+		switch {
+		case len(schema.Properties) > 0:
+			typ = OpenAPI_Type_Object
+		case schema.Items != nil:
+			typ = OpenAPI_Type_Array
+		default:
+			typ = OpenAPI_Type_String
+		}
+	}
+	if typ == OpenAPI_Type_Integer {
+		typ = "int" // lineschema 生成案例时只识别 int
+	}
+	p = Parameter{
+		Fullname:    fullname,
+		Title:       schema.Title,
+		Type:        typ,
+		Required:    required,
+		Description: schema.Description,
+		Default:     openAPIExampleString(schema.Default),
+		Example:     openAPIExampleString(schema.Example),
+	}
+	if p.Example == "" && len(schema.Examples) > 0 {
+		p.Example = openAPIExampleString(schema.Examples[0])
+	}
+	if schema.Deprecated {
+		p.Deprecated = "true"
+	}
+	if len(schema.Enum) > 0 {
+		enum := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			enum = append(enum, openAPIExampleString(v))
+		}
+		p.Enum = strings.Join(enum, ",")
+		p.EnumNames = strings.Join(schema.XEnumNames, ",")
+	}
+	p.Schema = Schema{
+		Title:       p.Title,
+		Description: p.Description,
+		Type:        typ,
+		Example:     p.Example,
+		Deprecated:  schema.Deprecated,
+		Required:    required,
+		Enum:        p.Enum,
+		EnumNames:   p.EnumNames,
+		Default:     p.Default,
+		Pattern:     schema.Pattern,
+		UniqueItems: schema.UniqueItems,
+		ReadOnly:    schema.ReadOnly,
+		WriteOnly:   schema.WriteOnly,
+	}
+	if schema.Nullable || schema.Type.Has(OpenAPI_Type_Null) {
+		p.Schema.Nullable = "true"
+	}
+	if schema.Format != "" {
+		p.SetFormat(schema.Format)
+	}
+	if schema.MultipleOf != nil {
+		p.Schema.MultipleOf = int(*schema.MultipleOf)
+	}
+	if schema.Maximum != nil {
+		p.Schema.Maximum = int(*schema.Maximum)
+		p.Schema.ExclusiveMaximum = cast.ToBool(schema.ExclusiveMaximum)
+	} else if v, ok := schema.ExclusiveMaximum.(float64); ok { // 3.1 exclusiveMaximum 为数值
+		p.Schema.Maximum, p.Schema.ExclusiveMaximum = int(v), true
+	}
+	if schema.Minimum != nil {
+		p.Schema.Minimum = intPtr(int(*schema.Minimum))
+		p.Schema.ExclusiveMinimum = cast.ToBool(schema.ExclusiveMinimum)
+	} else if v, ok := schema.ExclusiveMinimum.(float64); ok {
+		p.Schema.Minimum, p.Schema.ExclusiveMinimum = intPtr(int(v)), true
+	}
+	if schema.MaxLength != nil {
+		p.Schema.MaxLength = *schema.MaxLength
+	}
+	if schema.MinLength != nil {
+		p.Schema.MinLength = *schema.MinLength
+	}
+	if schema.MaxItems != nil {
+		p.Schema.MaxItems = *schema.MaxItems
+	}
+	if schema.MinItems != nil {
+		p.Schema.MinItems = *schema.MinItems
+	}
+	if schema.MaxProperties != nil {
+		p.Schema.MaxProperties = *schema.MaxProperties
+	}
+	if schema.MinProperties != nil {
+		p.Schema.MinProperties = *schema.MinProperties
+	}
+	p.FormatField()
+	return p
+}
+
+// mergeOpenAPIAllOf 合并 allOf 子schema 的属性
+func mergeOpenAPIAllOf(schema *OpenAPISchema) *OpenAPISchema {
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+	merged := *schema
+	merged.AllOf = nil
+	for _, sub := range schema.AllOf {
+		sub = mergeOpenAPIAllOf(sub)
+		if merged.Type == nil {
+			merged.Type = sub.Type
+		}
+		if len(sub.Properties) > 0 && merged.Properties == nil {
+			merged.Properties = make(map[string]*OpenAPISchema)
+		}
+		for name, property := range sub.Properties {
+			merged.Properties[name] = property
+		}
+		merged.Required = append(merged.Required, sub.Required...)
+		if merged.Items == nil {
+			merged.Items = sub.Items
+		}
+		if merged.Title == "" {
+			merged.Title = sub.Title
+		}
+		if merged.Description == "" {
+			merged.Description = sub.Description
+		}
+	}
+	return &merged
+}
+
+// selectOpenAPIMediaType 优先选择json格式
+func selectOpenAPIMediaType(content map[string]*OpenAPIMediaType) (contentType string, mediaType *OpenAPIMediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	for _, key := range sortedKeys(content) {
+		if strings.Contains(strings.ToLower(key), "json") {
+			return key, content[key]
+		}
+	}
+	keys := sortedKeys(content)
+	return keys[0], content[keys[0]]
+}
+
+// selectOpenAPIResponseCode 优先选择 200,其次第一个 2xx,最后 default
+func selectOpenAPIResponseCode(responses map[string]*OpenAPIResponse) (code string) {
+	if _, ok := responses["200"]; ok {
+		return "200"
+	}
+	codes := sortedKeys(responses)
+	for _, c := range codes {
+		if strings.HasPrefix(c, "2") {
+			return c
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return "default"
+	}
+	if len(codes) > 0 {
+		return codes[0]
+	}
+	return ""
+}
+
+func openAPIMediaTypeExamples(mediaType *OpenAPIMediaType) (examples map[string]*OpenAPIExample) {
+	examples = make(map[string]*OpenAPIExample)
+	for key, example := range mediaType.Examples {
+		if example != nil {
+			examples[key] = example
+		}
+	}
+	if len(examples) == 0 && mediaType.Example != nil {
+		examples["example1"] = &OpenAPIExample{Value: mediaType.Example}
+	}
+	return examples
+}
+
+func openAPIExampleBody(value any) (body string) {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return MakeBody(value)
+}
+
+func openAPIExampleString(value any) (s string) {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return cast.ToString(value)
+}
+
+// refResolver 展开文档内部 $ref,循环引用和外部引用记录为告警
+type refResolver struct {
+	root     map[string]any
+	warnings *ImportWarnings
+	stack    []string
+}
+
+func (r *refResolver) resolve(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if ref, ok := val["$ref"].(string); ok {
+			return r.resolveRef(ref, val)
+		}
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = r.resolve(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = r.resolve(child)
+		}
+		return out
+	}
+	return v
+}
+
+func (r *refResolver) resolveRef(ref string, node map[string]any) any {
+	siblings := make(map[string]any)
+	for k, child := range node {
+		if k != "$ref" {
+			siblings[k] = r.resolve(child)
+		}
+	}
+	if containsString(r.stack, ref) {
+		r.warnings.Add(ref, "circular $ref is replaced by an empty object")
+		siblings["type"] = OpenAPI_Type_Object
+		return siblings
+	}
+	target, err := jsonPointerGet(r.root, ref)
+	if err != nil {
+		r.warnings.Add(ref, "%s", err.Error())
+		return siblings
+	}
+	r.stack = append(r.stack, ref)
+	resolved := r.resolve(target)
+	r.stack = r.stack[:len(r.stack)-1]
+	resolvedMap, ok := resolved.(map[string]any)
+	if !ok || len(siblings) == 0 {
+		return resolved
+	}
+	for k, child := range siblings { // $ref 同级属性覆盖引用内容(3.1 支持)
+		resolvedMap[k] = child
+	}
+	return resolvedMap
+}
+
+func jsonPointerGet(root map[string]any, ref string) (v any, err error) {
+	if !strings.HasPrefix(ref, "#/") {
+		err = errors.Errorf("external $ref %s is not supported", ref)
+		return nil, err
+	}
+	var cur any = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token, _ = url.PathUnescape(token)
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := cur.(type) {
+		case map[string]any:
+			child, ok := node[token]
+			if !ok {
+				err = errors.Errorf("$ref %s not found", ref)
+				return nil, err
+			}
+			cur = child
+		case []any:
+			i, e := cast.ToIntE(token)
+			if e != nil || i < 0 || i >= len(node) {
+				err = errors.Errorf("$ref %s not found", ref)
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			err = errors.Errorf("$ref %s not found", ref)
+			return nil, err
+		}
+	}
+	return cur, nil
+}
+
+// normalizeYamlValue yaml 中非字符串 key(如响应码 200)转换为字符串 key
+func normalizeYamlValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			val[k] = normalizeYamlValue(child)
+		}
+		return val
+	case map[any]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[fmt.Sprint(k)] = normalizeYamlValue(child)
+		}
+		return out
+	case []any:
+		for i, child := range val {
+			val[i] = normalizeYamlValue(child)
+		}
+		return val
+	}
+	return v
+}
+
+func convertByJson(src any, dst any) (err error) {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func sortedKeys[V any](m map[string]V) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,197 @@
+package apidocbuilder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestOpenAPI2ServiceRoundTrip(t *testing.T) {
+	b, err := apidocbuilder.Service2OpenAPIJson(openAPIService)
+	require.NoError(t, err)
+	service, warnings, err := apidocbuilder.OpenAPI2Service(b)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.Equal(t, "dev", service.Servers[0].Name)
+	api, err := service.GetApiByName("userList")
+	require.NoError(t, err)
+	require.Equal(t, "POST", api.Method)
+	require.Equal(t, "user", api.Group)
+
+	names := make([]string, 0)
+	for _, p := range api.ResponseBody {
+		names = append(names, p.Fullname)
+	}
+	require.Equal(t, []string{"code", "data.items[].email", "data.items[].name", "data.tags[]"}, names)
+	status := api.RequestBody[1]
+	require.Equal(t, "status", status.Fullname)
+	require.Equal(t, "1,2", status.Enum)
+	require.Equal(t, "启用,禁用", status.EnumNames)
+	require.Len(t, api.Examples, 1)
+	require.JSONEq(t, `{"pageSize":10}`, api.Examples[0].RequestBody)
+}
+
+const swagger2Yaml = `
+swagger: "2.0"
+info:
+  title: pet store
+  version: 1.0.0
+host: petstore.swagger.io
+basePath: /v2
+schemes: [https]
+paths:
+  /pet/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        type: integer
+    get:
+      operationId: getPetById
+      tags: [pet]
+      produces: [application/json, application/xml]
+      parameters:
+        - name: tags
+          in: query
+          type: array
+          items:
+            type: string
+          collectionFormat: multi
+      responses:
+        200:
+          description: ok
+          schema:
+            $ref: "#/definitions/Pet"
+        404:
+          description: not found
+definitions:
+  Pet:
+    type: object
+    required: [name]
+    properties:
+      name:
+        type: string
+      category:
+        $ref: "#/definitions/Category"
+  Category:
+    type: object
+    properties:
+      id:
+        type: integer
+        format: int64
+      parent:
+        $ref: "#/definitions/Category"
+`
+
+func TestSwagger2Service(t *testing.T) {
+	service, warnings, err := apidocbuilder.OpenAPI2Service([]byte(swagger2Yaml))
+	require.NoError(t, err)
+	require.Equal(t, "https://petstore.swagger.io/v2", service.Servers[0].URL)
+	api, err := service.GetApiByName("getPetById")
+	require.NoError(t, err)
+	require.Equal(t, "path", api.Query[0].Position)
+	require.Equal(t, "form", api.Query[1].Serialize)
+	require.Equal(t, "true", api.Query[1].Explode)
+
+	names := make([]string, 0)
+	for _, p := range api.ResponseBody {
+		names = append(names, p.Fullname)
+	}
+	require.Equal(t, []string{"category.id", "category.parent", "name"}, names)
+	require.True(t, api.ResponseBody[2].Required)
+	require.NotEmpty(t, warnings) // 循环引用、多余响应码、多内容格式
+}
+
+const openAPINoOperationIdYaml = `
+openapi: 3.0.3
+info:
+  title: file
+  version: 1.0.0
+paths:
+  /file/{id}/meta.json:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        200:
+          description: ok
+    delete:
+      responses:
+        204:
+          description: deleted
+`
+
+func TestOpenAPI2ServiceApiNameAndIntegerType(t *testing.T) {
+	service, _, err := apidocbuilder.OpenAPI2Service([]byte(openAPINoOperationIdYaml))
+	require.NoError(t, err)
+	get, err := service.GetApiByName("getFileIdMetaJson")
+	require.NoError(t, err)
+	require.Equal(t, "int", get.Query[0].Type) // 与推断参数一致,生成案例时识别为整数
+	_, err = service.GetApiByName("deleteFileIdMetaJson")
+	require.NoError(t, err)
+}
+
+const openAPIOneOfYaml = `
+openapi: 3.1.0
+info:
+  title: pay
+  version: 1.0.0
+paths:
+  /pay:
+    post:
+      operationId: pay
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                method:
+                  oneOf:
+                    - title: card
+                      type: object
+                      properties:
+                        cardNo:
+                          type: string
+                    - title: wallet
+                      type: object
+                      properties:
+                        walletId:
+                          type: string
+                    - type: string
+                extra:
+                  type: object
+                  additionalProperties:
+                    type: string
+                strict:
+                  type: object
+                  additionalProperties: false
+                  properties:
+                    id:
+                      type: string
+      responses:
+        200:
+          description: ok
+`
+
+func TestOpenAPI2ServiceSchemaWarnings(t *testing.T) {
+	service, warnings, err := apidocbuilder.OpenAPI2Service([]byte(openAPIOneOfYaml))
+	require.NoError(t, err)
+	api, err := service.GetApiByName("pay")
+	require.NoError(t, err)
+	names := make([]string, 0)
+	for _, p := range api.RequestBody {
+		names = append(names, p.Fullname)
+	}
+	require.Equal(t, []string{"extra", "method", "method.cardNo", "strict.id"}, names)
+	require.Equal(t, []string{
+		"POST /pay requestBody: schema extra: additionalProperties is not supported, only declared properties are imported",
+		"POST /pay requestBody: schema method: only the first oneOf alternative is imported, ignored:oneOf[1](wallet),oneOf[2](string)",
+	}, strings.Split(warnings.String(), "\n"))
+}
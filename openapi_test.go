@@ -0,0 +1,93 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+	"github.com/tidwall/gjson"
+)
+
+var openAPIService = apidocbuilder.Service{
+	Name:     "user",
+	Title:    "用户服务",
+	Version:  "1.2.0",
+	License:  "MIT",
+	Security: "bearer",
+	Contacts: []apidocbuilder.Contact{{Name: "admin", Email: "admin@example.com"}},
+	Servers: apidocbuilder.Servers{
+		{Name: "dev", Description: "开发环境", URL: "http://dev.api.com"},
+	},
+	Apis: apidocbuilder.Apis{
+		{
+			Name:               "userList",
+			Group:              "user",
+			Title:              "用户列表",
+			Method:             "post",
+			Path:               "/user/:id/list",
+			RequestContentType: apidocbuilder.Header_Value_Content_Type_Json,
+			Query: apidocbuilder.Query{
+				{Fullname: "ids", Type: "string", Serialize: "form", Explode: "false"},
+			},
+			RequestBody: apidocbuilder.Parameters{
+				{Fullname: "pageSize", Type: "int", Required: true, Default: "10"},
+				{Fullname: "status", Type: "int", Enum: "1,2", EnumNames: "启用,禁用"},
+			},
+			ResponseBody: apidocbuilder.Parameters{
+				{Fullname: "code", Type: "string"},
+				{Fullname: "data.items[].name", Type: "string", Required: true, Title: "名称"},
+				{Fullname: "data.items[].email", Type: "string", Schema: apidocbuilder.Schema{Format: apidocbuilder.Format{"email"}}},
+				{Fullname: "data.tags[]", Type: "string"},
+			},
+			Examples: apidocbuilder.Examples{
+				{Title: "正常", RequestBody: `{"pageSize":10}`, Response: `{"code":"0"}`},
+			},
+		},
+	},
+}
+
+func TestService2OpenAPIJson(t *testing.T) {
+	b, err := apidocbuilder.Service2OpenAPIJson(openAPIService)
+	require.NoError(t, err)
+	s := string(b)
+	require.Equal(t, "3.1.0", gjson.Get(s, "openapi").String())
+	require.Equal(t, "bearer", gjson.Get(s, "components.securitySchemes.bearerAuth.scheme").String())
+
+	operation := gjson.Get(s, `paths./user/{id}/list.post`)
+	require.True(t, operation.Exists())
+	require.Equal(t, "userList", operation.Get("operationId").String())
+	require.Equal(t, "form", operation.Get(`parameters.#(name=="ids").style`).String())
+	require.False(t, operation.Get(`parameters.#(name=="ids").explode`).Bool())
+	require.True(t, operation.Get(`parameters.#(name=="id").required`).Bool())
+
+	reqSchema := operation.Get(`requestBody.content.application/json.schema`)
+	require.Equal(t, "integer", reqSchema.Get("properties.pageSize.type").String())
+	require.Equal(t, `[1,2]`, reqSchema.Get("properties.status.enum|@ugly").Raw)
+	require.Equal(t, `["pageSize"]`, reqSchema.Get("required|@ugly").Raw)
+
+	respSchema := operation.Get(`responses.200.content.application/json.schema`)
+	items := respSchema.Get("properties.data.properties.items")
+	require.Equal(t, "array", items.Get("type").String())
+	require.Equal(t, "email", items.Get("items.properties.email.format").String())
+	require.Equal(t, `["name"]`, items.Get("items.required|@ugly").Raw)
+	require.Equal(t, "string", respSchema.Get("properties.data.properties.tags.items.type").String())
+}
+
+func TestService2OpenAPIYaml(t *testing.T) {
+	b, err := apidocbuilder.Service2OpenAPIYaml(openAPIService)
+	require.NoError(t, err)
+	s := string(b)
+	require.Contains(t, s, "openapi: 3.1.0")
+	require.Contains(t, s, "/user/{id}/list:")
+	require.Contains(t, s, "paths:")
+}
+
+func TestParametersOpenAPISchemaNegativeMaximum(t *testing.T) {
+	minimum := -10
+	ps := apidocbuilder.Parameters{{Fullname: "offset", Type: "int", Schema: apidocbuilder.Schema{Minimum: &minimum, Maximum: -1}}}
+	schema := ps.OpenAPISchema().Properties["offset"]
+	require.NotNil(t, schema.Maximum)
+	require.Equal(t, float64(-1), *schema.Maximum)
+	require.Equal(t, float64(-10), *schema.Minimum)
+	require.NotEmpty(t, ps.ValidateJson([]byte(`{"offset":0}`)))
+}
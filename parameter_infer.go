@@ -0,0 +1,125 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/spf13/cast"
+)
+
+// Json2Parameters 根据json案例推断参数,类型取自案例值,案例值记录到 Example
+func Json2Parameters(jsonStr string) (params Parameters, err error) {
+	var data any
+	err = json.Unmarshal([]byte(jsonStr), &data)
+	if err != nil {
+		return nil, err
+	}
+	params = Value2Parameters(data, "")
+	return params, nil
+}
+
+// Value2Parameters 根据已解析的json值推断参数,数组元素合并为一个 [] 参数
+func Value2Parameters(value any, prefix string) (params Parameters) {
+	params = make(Parameters, 0)
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			if prefix != "" {
+				params.Add(Parameter{Fullname: prefix, Type: OpenAPI_Type_Object})
+			}
+			return params
+		}
+		for _, key := range sortedKeys(v) {
+			fullname := key
+			if prefix != "" {
+				fullname = fmt.Sprintf("%s.%s", prefix, key)
+			}
+			params.Add(Value2Parameters(v[key], fullname)...)
+		}
+	case []any:
+		if len(v) == 0 {
+			if prefix != "" {
+				params.Add(Parameter{Fullname: prefix, Type: OpenAPI_Type_Array})
+			}
+			return params
+		}
+		itemPrefix := fmt.Sprintf("%s[]", prefix)
+		for _, item := range v {
+			params = params.MergeInferred(Value2Parameters(item, itemPrefix)...)
+		}
+	default:
+		typ, example := inferValueType(v)
+		params.Add(Parameter{Fullname: prefix, Type: typ, Example: example})
+	}
+	return params
+}
+
+// Value2Parameter 推断单个字符串值的类型(query、header、表单等场景,值都为字符串)
+func Value2Parameter(fullname string, value string) (p Parameter) {
+	typ := OpenAPI_Type_String
+	switch {
+	case value == "true" || value == "false":
+		typ = OpenAPI_Type_Boolean
+	case intStringReg.MatchString(value):
+		typ = "int"
+	case floatStringReg.MatchString(value):
+		typ = OpenAPI_Type_Number
+	}
+	p = Parameter{Fullname: fullname, Type: typ, Example: value}
+	p.FormatField()
+	return p
+}
+
+var (
+	intStringReg   = regexp.MustCompile(`^-?(0|[1-9]\d{0,17})$`) // 前导0(如编号 007)按字符串处理
+	floatStringReg = regexp.MustCompile(`^-?(0|[1-9]\d*)\.\d+$`)
+)
+
+// MergeInferred 合并多个案例推断出的参数:新增缺少的参数,null 类型被具体类型替换
+func (ps Parameters) MergeInferred(others ...Parameter) Parameters {
+	for _, other := range others {
+		exists := false
+		for i := range ps {
+			if ps[i].Fullname != other.Fullname {
+				continue
+			}
+			exists = true
+			if ps[i].Type == OpenAPI_Type_Null && other.Type != OpenAPI_Type_Null {
+				ps[i].Type = other.Type
+				ps[i].Example = other.Example
+			}
+			if ps[i].Type == "int" && other.Type == OpenAPI_Type_Number { // 整数和小数混合时取小数
+				ps[i].Type = OpenAPI_Type_Number
+			}
+			break
+		}
+		if !exists {
+			ps = append(ps, other)
+		}
+	}
+	return ps
+}
+
+func inferValueType(value any) (typ string, example string) {
+	switch v := value.(type) {
+	case nil:
+		return OpenAPI_Type_Null, ""
+	case bool:
+		return OpenAPI_Type_Boolean, cast.ToString(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return "int", cast.ToString(int64(v))
+		}
+		return OpenAPI_Type_Number, cast.ToString(v)
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "int", v.String()
+		}
+		return OpenAPI_Type_Number, v.String()
+	case string:
+		return OpenAPI_Type_String, v
+	}
+	return OpenAPI_Type_String, cast.ToString(value)
+}
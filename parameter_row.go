@@ -0,0 +1,148 @@
+package apidocbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// ParameterRow 参数树按先序展开后的一行,文档中按层级缩进展示
+type ParameterRow struct {
+	// 中间层未单独定义时只有 Fullname、Name、Type
+	Parameter
+	// 层级,顶层为 0
+	Depth int
+	// 展示的名称,数组元素为 []
+	Label string
+	// 是否有下级行,html 文档中可折叠
+	HasChildren bool
+}
+
+type ParameterRows []ParameterRow
+
+// Rows 按层级展开,下级紧跟在上级之后;未定义的数组元素(data.items[] 本身)不单独成行,其属性直接作为数组的下级;
+// 无法放入树中的参数(全称重复、冲突等)以全称平铺在最后,不丢失
+func (ps Parameters) Rows() (rows ParameterRows) {
+	return ps.Tree().Rows()
+}
+
+func (h Header) Rows() (rows ParameterRows) {
+	return Parameters(h).Rows()
+}
+
+func (q Query) Rows() (rows ParameterRows) {
+	return Parameters(q).Rows()
+}
+
+func (tree *ParameterTree) Rows() (rows ParameterRows) {
+	rows = make(ParameterRows, 0)
+	for _, child := range tree.Root.Children() {
+		rows = child.appendRows(rows, 0)
+	}
+	for _, unplaced := range tree.Unplaced {
+		rows = append(rows, ParameterRow{Parameter: unplaced.Parameter, Label: parameterTreeFullname(unplaced.Parameter)})
+	}
+	return rows
+}
+
+func (node *ParameterNode) appendRows(rows ParameterRows, depth int) ParameterRows {
+	isItems := node.parent != nil && node.parent.Items == node
+	if isItems && node.Parameter == nil {
+		for _, child := range node.Children() {
+			rows = child.appendRows(rows, depth)
+		}
+		return rows
+	}
+	row := ParameterRow{Depth: depth, Label: node.Name}
+	if isItems {
+		row.Label = "[]"
+	}
+	if node.Parameter != nil {
+		row.Parameter = *node.Parameter
+	} else {
+		row.Parameter = Parameter{Fullname: node.Fullname(), Name: node.Name, Type: node.Kind()}
+	}
+	index := len(rows)
+	rows = append(rows, row)
+	for _, child := range node.Children() {
+		rows = child.appendRows(rows, depth+1)
+	}
+	rows[index].HasChildren = len(rows) > index+1
+	return rows
+}
+
+// IndentedName markdown 表格中的参数名,下级参数前加全角空格缩进
+func (row ParameterRow) IndentedName() (name string) {
+	if row.Depth == 0 {
+		return row.Label
+	}
+	return strings.Repeat("\u3000", row.Depth) + "└ " + row.Label
+}
+
+// EnumLabels 枚举值及名称,如 1:启用,2:禁用
+func (row ParameterRow) EnumLabels() (labels string) {
+	enum := parameterEnum(row.Parameter)
+	if len(enum) == 0 {
+		return ""
+	}
+	names := splitEnum(firstNotEmpty(row.EnumNames, row.Schema.EnumNames))
+	items := make([]string, 0, len(enum))
+	for i, v := range enum {
+		if i < len(names) && names[i] != "" && names[i] != v {
+			v = fmt.Sprintf("%s:%s", v, names[i])
+		}
+		items = append(items, v)
+	}
+	return strings.Join(items, ",")
+}
+
+// Constraints 长度、取值范围、元素个数、正则等约束的简要说明
+func (row ParameterRow) Constraints() (constraints string) {
+	schema := parameter2OpenAPISchema(row.Parameter)
+	items := make([]string, 0)
+	add := func(name string, min string, max string) {
+		switch {
+		case min != "" && max != "":
+			items = append(items, fmt.Sprintf("%s %s~%s", name, min, max))
+		case min != "":
+			items = append(items, fmt.Sprintf("%s ≥%s", name, min))
+		case max != "":
+			items = append(items, fmt.Sprintf("%s ≤%s", name, max))
+		}
+	}
+	add("长度", intText(schema.MinLength), intText(schema.MaxLength))
+	add("取值", floatText(schema.Minimum), floatText(schema.Maximum))
+	if schema.ExclusiveMinimum != nil {
+		items = append(items, fmt.Sprintf("取值 >%s", cast.ToString(schema.ExclusiveMinimum)))
+	}
+	if schema.ExclusiveMaximum != nil {
+		items = append(items, fmt.Sprintf("取值 <%s", cast.ToString(schema.ExclusiveMaximum)))
+	}
+	if schema.MultipleOf != nil {
+		items = append(items, fmt.Sprintf("%s 的倍数", floatText(schema.MultipleOf)))
+	}
+	add("元素个数", intText(schema.MinItems), intText(schema.MaxItems))
+	if schema.UniqueItems {
+		items = append(items, "元素唯一")
+	}
+	add("属性个数", intText(schema.MinProperties), intText(schema.MaxProperties))
+	if schema.Pattern != "" {
+		items = append(items, fmt.Sprintf("正则 `%s`", strings.ReplaceAll(schema.Pattern, "|", `\|`))) // 避免破坏 markdown 表格
+	}
+	return strings.Join(items, "; ")
+}
+
+func intText(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return cast.ToString(*v)
+}
+
+func floatText(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return cast.ToString(*v)
+}
@@ -0,0 +1,95 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestParameterRows(t *testing.T) {
+	minimum := 1
+	ps := apidocbuilder.Parameters{
+		{Fullname: "code", Type: "int", Enum: "0,1", EnumNames: "成功,失败"},
+		{Fullname: "data.users[].name", Type: "string", Required: true, Schema: apidocbuilder.Schema{MinLength: 1, MaxLength: 20}},
+		{Fullname: "data.users[].pagination.total", Type: "int", Schema: apidocbuilder.Schema{Minimum: &minimum}},
+		{Fullname: "data.tags[]", Type: "string", Schema: apidocbuilder.Schema{Pattern: "^a|b$"}},
+	}
+	rows := ps.Rows()
+	labels := make([]string, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, row.IndentedName())
+	}
+	require.Equal(t, []string{
+		"code",
+		"data",
+		"\u3000└ users",
+		"\u3000\u3000└ name",
+		"\u3000\u3000└ pagination",
+		"\u3000\u3000\u3000└ total",
+		"\u3000└ tags",
+		"\u3000\u3000└ []",
+	}, labels)
+
+	data := rows[1]
+	require.True(t, data.HasChildren)
+	require.Equal(t, "object", data.Type)
+	require.Equal(t, "data", data.Fullname)
+	require.Equal(t, "array", rows[2].Type)
+	require.Equal(t, "data.users[].pagination", rows[4].Fullname)
+	require.False(t, rows[5].HasChildren)
+
+	require.Equal(t, "0:成功,1:失败", rows[0].EnumLabels())
+	require.Equal(t, "", rows[3].EnumLabels())
+	require.Equal(t, "长度 1~20", rows[3].Constraints())
+	require.Equal(t, "取值 ≥1", rows[5].Constraints())
+	require.Equal(t, "正则 `^a\\|b$`", rows[7].Constraints())
+}
+
+func TestApi2MarkdownNestedParameters(t *testing.T) {
+	api := apidocbuilder.Api{
+		Name:   "listUser",
+		Method: "GET",
+		Path:   "/users",
+		ResponseBody: apidocbuilder.Parameters{
+			{Fullname: "data.users[].id", Type: "int", Required: true, Title: "用户ID"},
+			{Fullname: "data.users[].status", Type: "int", Enum: "1,2", EnumNames: "启用,禁用"},
+		},
+	}
+	out, err := apidocbuilder.Api2Markdown(api)
+	require.NoError(t, err)
+	s := string(out)
+	require.Contains(t, s, "|data|object|")
+	require.Contains(t, s, "|\u3000\u3000└ id|int||true|false|用户ID|")
+	require.Contains(t, s, "|1:启用,2:禁用|")
+
+	htm, err := apidocbuilder.Markdown2HTML(out)
+	require.NoError(t, err)
+	require.Contains(t, string(htm), "\u3000\u3000└ status")
+	require.Contains(t, string(htm), "param-toggle")
+}
+
+func TestParameterRowsUnplaced(t *testing.T) {
+	ps := apidocbuilder.Parameters{
+		{Fullname: "data.items[].id", Type: "int"},
+		{Fullname: "data.items.count", Type: "int", Title: "总数"},
+		{Fullname: "dup", Type: "string"},
+		{Fullname: "dup", Type: "int"},
+		{Name: "page", Type: "int"},
+	}
+	rows := ps.Rows()
+	labels := make([]string, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, row.IndentedName()+" "+row.Type)
+	}
+	require.Equal(t, []string{
+		"data object",
+		"\u3000└ items array",
+		"\u3000\u3000└ id int",
+		"dup string",
+		"page int",
+		"data.items.count int",
+		"dup int",
+	}, labels)
+	require.Equal(t, "总数", rows[5].Title)
+}
@@ -0,0 +1,448 @@
+package apidocbuilder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PARAMETER_NODE_OBJECT = "object"
+	PARAMETER_NODE_ARRAY  = "array"
+	PARAMETER_NODE_SCALAR = "scalar"
+)
+
+var (
+	ERROR_NOT_FOUND_PARAMETER     = errors.New("not found parameter")
+	ERROR_PARAMETER_EXISTS        = errors.New("parameter exists")
+	ERROR_PARAMETER_TREE_CONFLICT = errors.New("parameter tree conflict")
+)
+
+// ParameterNode 参数树节点,对象的属性在 Properties 中,数组的元素为 Items(名称为空)
+type ParameterNode struct {
+	// 属性名称,数组元素为空
+	Name string
+	// 对应的扁平参数,data.items[].id 中的 data、data.items 未单独定义时为 nil
+	Parameter  *Parameter
+	Properties []*ParameterNode
+	Items      *ParameterNode
+	parent     *ParameterNode
+	order      int // 在扁平参数中的位置,转换回扁平参数时保持原顺序
+}
+
+// Kind object、array、scalar,有下级节点时以下级为准,否则取参数类型
+func (node *ParameterNode) Kind() string {
+	switch {
+	case node.Items != nil:
+		return PARAMETER_NODE_ARRAY
+	case len(node.Properties) > 0 || node.parent == nil:
+		return PARAMETER_NODE_OBJECT
+	case node.Parameter != nil:
+		typ := strings.TrimSpace(firstNotEmpty(node.Parameter.Type, node.Parameter.Schema.Type))
+		if strings.HasPrefix(typ, "[]") {
+			return PARAMETER_NODE_ARRAY
+		}
+		switch oaType, _ := openAPIType(typ); oaType {
+		case OpenAPI_Type_Array:
+			return PARAMETER_NODE_ARRAY
+		case OpenAPI_Type_Object:
+			return PARAMETER_NODE_OBJECT
+		}
+	}
+	return PARAMETER_NODE_SCALAR
+}
+
+// Fullname 节点的参数全称,如 data.items[].id
+func (node *ParameterNode) Fullname() (fullname string) {
+	if node.parent == nil {
+		return ""
+	}
+	parent := node.parent.Fullname()
+	if node.parent.Items == node {
+		return parent + "[]"
+	}
+	if parent == "" {
+		return node.Name
+	}
+	return parent + "." + node.Name
+}
+
+// Children 属性及数组元素
+func (node *ParameterNode) Children() (children []*ParameterNode) {
+	children = append(children, node.Properties...)
+	if node.Items != nil {
+		children = append(children, node.Items)
+	}
+	return children
+}
+
+func (node *ParameterNode) property(name string) *ParameterNode {
+	for _, child := range node.Properties {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// detach 从上级中移除
+func (node *ParameterNode) detach() {
+	parent := node.parent
+	if parent == nil {
+		return
+	}
+	if parent.Items == node {
+		parent.Items = nil
+	}
+	for i, child := range parent.Properties {
+		if child == node {
+			parent.Properties = append(parent.Properties[:i:i], parent.Properties[i+1:]...)
+			break
+		}
+	}
+	node.parent = nil
+}
+
+// walk 先序遍历,fn 返回 false 时不再遍历下级
+func (node *ParameterNode) walk(fn func(node *ParameterNode) bool) {
+	if !fn(node) {
+		return
+	}
+	for _, child := range node.Children() {
+		child.walk(fn)
+	}
+}
+
+// ParameterTree 扁平参数(data.items[].id)的树形表示,与扁平参数可无损互转
+type ParameterTree struct {
+	Root *ParameterNode
+	// 无法放入树中的参数(全称重复、与已有节点冲突、没有名称),转换回扁平参数时在原位置保留
+	Unplaced ParameterUnplacedList
+	seq      int
+}
+
+// ParameterUnplaced 未放入树中的参数及原因
+type ParameterUnplaced struct {
+	Parameter Parameter
+	Err       error
+	order     int
+}
+
+// NewParameterTree 扁平参数转换为树,全称为空时使用名称;无法放入树中的参数记录在 Unplaced 中,可通过 Err 获取
+func NewParameterTree(ps Parameters) (tree *ParameterTree) {
+	tree = &ParameterTree{Root: &ParameterNode{}}
+	for _, p := range ps {
+		p := p
+		fullname := parameterTreeFullname(p)
+		node, err := tree.ensure(fullname)
+		switch {
+		case err != nil:
+		case fullname == "":
+			err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "parameter has no fullname or name")
+		case node.Parameter != nil:
+			err = errors.WithMessagef(ERROR_PARAMETER_EXISTS, "fullname:%s", fullname)
+		}
+		if err != nil {
+			tree.Unplaced = append(tree.Unplaced, ParameterUnplaced{Parameter: p, Err: err, order: tree.nextOrder()})
+			continue
+		}
+		node.Parameter = &p
+		node.order = tree.nextOrder()
+	}
+	return tree
+}
+
+type ParameterUnplacedList []ParameterUnplaced
+
+func (list ParameterUnplacedList) Error() string {
+	messages := make([]string, 0, len(list))
+	for _, unplaced := range list {
+		messages = append(messages, fmt.Sprintf("%s: %s", parameterTreeFullname(unplaced.Parameter), unplaced.Err.Error()))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err 存在无法放入树中的参数时返回 Unplaced,否则返回 nil
+func (tree *ParameterTree) Err() (err error) {
+	if len(tree.Unplaced) == 0 {
+		return nil
+	}
+	return tree.Unplaced
+}
+
+func parameterTreeFullname(p Parameter) string {
+	return strings.TrimSpace(firstNotEmpty(p.Fullname, p.Name))
+}
+
+func (tree *ParameterTree) nextOrder() int {
+	tree.seq++
+	return tree.seq
+}
+
+// Parameters 转换回扁平参数,全称按节点位置重新生成,位置未变的参数保留原全称写法;Unplaced 中的参数原样保留
+func (tree *ParameterTree) Parameters() (ps Parameters) {
+	type ordered struct {
+		order     int
+		parameter Parameter
+	}
+	items := make([]ordered, 0)
+	tree.Walk(func(node *ParameterNode) bool {
+		if node.Parameter == nil {
+			return true
+		}
+		p := *node.Parameter
+		fullname := node.Fullname()
+		if original := parameterTreeFullname(p); canonicalFullname(original) != fullname {
+			if p.Name != "" && p.Name == lastFullnamePart(original) { // 名称由全称生成时同步修改
+				p.Name = lastFullnamePart(fullname)
+			}
+			p.Fullname = fullname
+		}
+		items = append(items, ordered{order: node.order, parameter: p})
+		return true
+	})
+	for _, unplaced := range tree.Unplaced {
+		items = append(items, ordered{order: unplaced.order, parameter: unplaced.Parameter})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].order < items[j].order })
+	ps = make(Parameters, 0, len(items))
+	for _, item := range items {
+		ps = append(ps, item.parameter)
+	}
+	return ps
+}
+
+func lastFullnamePart(fullname string) string {
+	return fullname[strings.LastIndex(fullname, ".")+1:]
+}
+
+// Walk 先序遍历所有节点(不含根节点),fn 返回 false 时不再遍历该节点的下级
+func (tree *ParameterTree) Walk(fn func(node *ParameterNode) bool) {
+	for _, child := range tree.Root.Children() {
+		child.walk(fn)
+	}
+}
+
+// Find 按全称查找节点,中间层节点同样可以查找
+func (tree *ParameterTree) Find(fullname string) (node *ParameterNode) {
+	node = tree.Root
+	for _, seg := range parseFullname(fullname) {
+		if seg.Name != "" {
+			node = node.property(seg.Name)
+			if node == nil {
+				return nil
+			}
+		}
+		for i := 0; i < seg.ArrayDepth; i++ {
+			node = node.Items
+			if node == nil {
+				return nil
+			}
+		}
+	}
+	return node
+}
+
+// ensure 查找节点,不存在时创建(包括中间层)
+func (tree *ParameterTree) ensure(fullname string) (node *ParameterNode, err error) {
+	node = tree.Root
+	for _, seg := range parseFullname(fullname) {
+		if seg.Name != "" {
+			child := node.property(seg.Name)
+			if child == nil {
+				if node.Items != nil {
+					err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "%s is array, can not add property %s", node.Fullname(), seg.Name)
+					return nil, err
+				}
+				child = &ParameterNode{Name: seg.Name, parent: node}
+				node.Properties = append(node.Properties, child)
+			}
+			node = child
+		}
+		for i := 0; i < seg.ArrayDepth; i++ {
+			if node.Items == nil {
+				if len(node.Properties) > 0 {
+					err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "%s is object, can not add items", node.Fullname())
+					return nil, err
+				}
+				node.Items = &ParameterNode{parent: node}
+			}
+			node = node.Items
+		}
+	}
+	return node, nil
+}
+
+// Insert 新增参数,缺少的中间层自动创建(不生成参数),已存在时返回 ERROR_PARAMETER_EXISTS
+func (tree *ParameterTree) Insert(p Parameter) (node *ParameterNode, err error) {
+	fullname := parameterTreeFullname(p)
+	if fullname == "" {
+		err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "parameter has no fullname or name")
+		return nil, err
+	}
+	if existing := tree.Find(fullname); existing != nil && existing.Parameter != nil {
+		err = errors.WithMessagef(ERROR_PARAMETER_EXISTS, "fullname:%s", fullname)
+		return nil, err
+	}
+	node, err = tree.ensure(fullname)
+	if err != nil {
+		return nil, err
+	}
+	node.Parameter = &p
+	node.order = tree.nextOrder()
+	return node, nil
+}
+
+// Remove 删除节点及其下级
+func (tree *ParameterTree) Remove(fullname string) (removed *ParameterNode, err error) {
+	removed = tree.Find(fullname)
+	if removed == nil || removed == tree.Root {
+		err = errors.WithMessagef(ERROR_NOT_FOUND_PARAMETER, "fullname:%s", fullname)
+		return nil, err
+	}
+	removed.detach()
+	return removed, nil
+}
+
+// Move 将 from 及其下级移动到 to,如 user.address -> address
+func (tree *ParameterTree) Move(from string, to string) (err error) {
+	node := tree.Find(from)
+	if node == nil || node == tree.Root {
+		err = errors.WithMessagef(ERROR_NOT_FOUND_PARAMETER, "fullname:%s", from)
+		return err
+	}
+	canonicalFrom, canonicalTo := canonicalFullname(from), canonicalFullname(to)
+	if canonicalTo == canonicalFrom || strings.HasPrefix(canonicalTo, canonicalFrom+".") || strings.HasPrefix(canonicalTo, canonicalFrom+"[]") {
+		err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "can not move %s to %s", from, to)
+		return err
+	}
+	if existing := tree.Find(to); existing != nil {
+		err = errors.WithMessagef(ERROR_PARAMETER_EXISTS, "fullname:%s", to)
+		return err
+	}
+	node.detach()
+	target, err := tree.ensure(to)
+	if err != nil {
+		tree.attach(tree.mustParent(from), node) // 恢复
+		return err
+	}
+	// 用移动的节点替换新建的空节点
+	parent, items := target.parent, target.parent.Items == target
+	target.detach()
+	tree.attachAs(parent, node, target.Name, items)
+	return nil
+}
+
+// Reprefix 将 oldPrefix 下的所有参数移动到 newPrefix 下,前缀为空表示顶层,如 "" -> data 包裹一层, data -> "" 去掉一层
+func (tree *ParameterTree) Reprefix(oldPrefix string, newPrefix string) (err error) {
+	source := tree.Find(oldPrefix)
+	if source == nil {
+		err = errors.WithMessagef(ERROR_NOT_FOUND_PARAMETER, "fullname:%s", oldPrefix)
+		return err
+	}
+	if len(source.Properties) == 0 {
+		err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "%s has no properties", oldPrefix)
+		return err
+	}
+	children := append([]*ParameterNode{}, source.Properties...)
+	for _, child := range children {
+		child.detach()
+	}
+	target, err := tree.ensure(newPrefix)
+	if err == nil && target.Items != nil {
+		err = errors.WithMessagef(ERROR_PARAMETER_TREE_CONFLICT, "%s is array", newPrefix)
+	}
+	if err == nil {
+		for _, child := range children {
+			if target.property(child.Name) != nil {
+				err = errors.WithMessagef(ERROR_PARAMETER_EXISTS, "fullname:%s", joinFullname(newPrefix, child.Name))
+				break
+			}
+		}
+	}
+	if err != nil {
+		for _, child := range children {
+			tree.attach(source, child)
+		}
+		return err
+	}
+	for _, child := range children {
+		tree.attach(target, child)
+	}
+	if source != tree.Root && source != target && source.Parameter == nil && len(source.Children()) == 0 {
+		source.detach() // 去掉已空的中间层
+	}
+	return nil
+}
+
+func joinFullname(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func (tree *ParameterTree) attach(parent *ParameterNode, node *ParameterNode) {
+	tree.attachAs(parent, node, node.Name, node.Name == "")
+}
+
+func (tree *ParameterTree) attachAs(parent *ParameterNode, node *ParameterNode, name string, items bool) {
+	node.parent = parent
+	if items {
+		node.Name = ""
+		parent.Items = node
+		return
+	}
+	node.Name = name
+	parent.Properties = append(parent.Properties, node)
+}
+
+func (tree *ParameterTree) mustParent(fullname string) *ParameterNode {
+	fullname = canonicalFullname(fullname)
+	if strings.HasSuffix(fullname, "[]") {
+		return tree.Find(strings.TrimSuffix(fullname, "[]"))
+	}
+	if i := strings.LastIndex(fullname, "."); i >= 0 {
+		return tree.Find(fullname[:i])
+	}
+	return tree.Root
+}
+
+// Schema 转换为嵌套的 Schema(Properties、Items)
+func (tree *ParameterTree) Schema() (schema *Schema) {
+	return tree.Root.Schema()
+}
+
+func (node *ParameterNode) Schema() (schema *Schema) {
+	schema = &Schema{}
+	if node.Parameter != nil {
+		p := node.Parameter.Copy()
+		p.completeSchema()
+		*schema = p.Schema
+		schema.Title = firstNotEmpty(p.Title, schema.Title)
+		schema.Properties, schema.Items = nil, nil
+	}
+	switch node.Kind() {
+	case PARAMETER_NODE_OBJECT:
+		schema.Type = OpenAPI_Type_Object
+		if len(node.Properties) > 0 {
+			schema.Properties = make(map[string]*Schema, len(node.Properties))
+			for _, child := range node.Properties {
+				schema.Properties[child.Name] = child.Schema()
+			}
+		}
+	case PARAMETER_NODE_ARRAY:
+		schema.Type = OpenAPI_Type_Array
+		if node.Items != nil {
+			schema.Items = node.Items.Schema()
+		}
+	}
+	return schema
+}
+
+// Tree 扁平参数转换为树
+func (ps Parameters) Tree() (tree *ParameterTree) {
+	return NewParameterTree(ps)
+}
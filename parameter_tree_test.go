@@ -0,0 +1,106 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func fullnames(ps apidocbuilder.Parameters) (names []string) {
+	for _, p := range ps {
+		names = append(names, p.Fullname)
+	}
+	return names
+}
+
+func TestParameterTree(t *testing.T) {
+	ps := apidocbuilder.Parameters{
+		{Fullname: "code", Name: "code", Type: "int", Required: true},
+		{Fullname: "data.items[].id", Name: "id", Type: "int"},
+		{Fullname: "data.items", Name: "items", Type: "array", Title: "列表"},
+		{Fullname: "data.items.[].name", Name: "name", Type: "string"},
+		{Fullname: "data.tags[]", Type: "string"},
+		{Fullname: "message", Type: "string"},
+	}
+	tree := ps.Tree()
+	require.Equal(t, ps, tree.Parameters())
+
+	items := tree.Find("data.items")
+	require.Equal(t, apidocbuilder.PARAMETER_NODE_ARRAY, items.Kind())
+	require.Equal(t, "列表", items.Parameter.Title)
+	require.Equal(t, apidocbuilder.PARAMETER_NODE_OBJECT, items.Items.Kind())
+	require.Equal(t, "data.items[].name", items.Items.Properties[1].Fullname())
+	data := tree.Find("data")
+	require.Nil(t, data.Parameter)
+	require.Equal(t, apidocbuilder.PARAMETER_NODE_OBJECT, data.Kind())
+	require.Equal(t, apidocbuilder.PARAMETER_NODE_SCALAR, tree.Find("data.tags[]").Kind())
+	require.Nil(t, tree.Find("data.notExists"))
+
+	schema := tree.Schema()
+	require.Equal(t, "object", schema.Type)
+	require.True(t, schema.Properties["code"].Required)
+	require.Equal(t, "array", schema.Properties["data"].Properties["items"].Type)
+	require.Equal(t, "列表", schema.Properties["data"].Properties["items"].Title)
+	require.Equal(t, "string", schema.Properties["data"].Properties["items"].Items.Properties["name"].Type)
+	require.Equal(t, "string", schema.Properties["data"].Properties["tags"].Items.Type)
+
+	// 插入
+	_, err := tree.Insert(apidocbuilder.Parameter{Fullname: "data.total", Type: "int"})
+	require.NoError(t, err)
+	_, err = tree.Insert(apidocbuilder.Parameter{Fullname: "code"})
+	require.ErrorIs(t, err, apidocbuilder.ERROR_PARAMETER_EXISTS)
+	_, err = tree.Insert(apidocbuilder.Parameter{Fullname: "data.items.total"})
+	require.ErrorIs(t, err, apidocbuilder.ERROR_PARAMETER_TREE_CONFLICT)
+
+	// 移动子树,名称随全称修改
+	require.NoError(t, tree.Move("data.items", "list"))
+	require.ErrorIs(t, tree.Move("list", "list[].sub"), apidocbuilder.ERROR_PARAMETER_TREE_CONFLICT)
+	require.ErrorIs(t, tree.Move("code", "message"), apidocbuilder.ERROR_PARAMETER_EXISTS)
+	moved := tree.Parameters()
+	require.Equal(t, []string{"code", "list[].id", "list", "list[].name", "data.tags[]", "message", "data.total"}, fullnames(moved))
+	require.Equal(t, "list", moved[2].Name)
+
+	// 删除
+	removed, err := tree.Remove("data.tags")
+	require.NoError(t, err)
+	require.Equal(t, "tags", removed.Name)
+	_, err = tree.Remove("data.tags")
+	require.ErrorIs(t, err, apidocbuilder.ERROR_NOT_FOUND_PARAMETER)
+
+	// 顶层包裹一层 data,再去掉
+	require.NoError(t, tree.Reprefix("", "result"))
+	require.Equal(t, []string{"result.code", "result.list[].id", "result.list", "result.list[].name", "result.message", "result.data.total"}, fullnames(tree.Parameters()))
+	require.NoError(t, tree.Reprefix("result", ""))
+	require.Nil(t, tree.Find("result"))
+	require.NoError(t, tree.Reprefix("data", ""))
+	require.Equal(t, []string{"code", "list[].id", "list", "list[].name", "message", "total"}, fullnames(tree.Parameters()))
+	_, err = tree.Insert(apidocbuilder.Parameter{Fullname: "extra.code", Type: "string"})
+	require.NoError(t, err)
+	require.ErrorIs(t, tree.Reprefix("extra", ""), apidocbuilder.ERROR_PARAMETER_EXISTS)
+	require.NotNil(t, tree.Find("extra.code")) // 冲突时保持原样
+}
+
+func TestParameterTreeUnplaced(t *testing.T) {
+	ps := apidocbuilder.Parameters{
+		{Fullname: "data.items[].id", Type: "int"},
+		{Fullname: "data.items.count", Type: "int"},
+		{Fullname: "dup", Type: "string", Title: "第一个"},
+		{Fullname: "dup", Type: "string", Title: "第二个"},
+		{Name: "page", Type: "int"},
+		{Type: "string"},
+	}
+	tree := ps.Tree()
+	require.Equal(t, ps, tree.Parameters())
+	require.NotNil(t, tree.Find("page"))
+	require.Equal(t, "第一个", tree.Find("dup").Parameter.Title)
+
+	require.Len(t, tree.Unplaced, 3)
+	require.ErrorIs(t, tree.Unplaced[0].Err, apidocbuilder.ERROR_PARAMETER_TREE_CONFLICT)
+	require.ErrorIs(t, tree.Unplaced[1].Err, apidocbuilder.ERROR_PARAMETER_EXISTS)
+	require.ErrorIs(t, tree.Unplaced[2].Err, apidocbuilder.ERROR_PARAMETER_TREE_CONFLICT)
+	require.ErrorContains(t, tree.Err(), "data.items.count")
+	require.NoError(t, apidocbuilder.Parameters{{Fullname: "id"}}.Tree().Err())
+	_, err := tree.Insert(apidocbuilder.Parameter{Type: "string"})
+	require.ErrorIs(t, err, apidocbuilder.ERROR_PARAMETER_TREE_CONFLICT)
+}
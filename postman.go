@@ -0,0 +1,457 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	Postman_Schema_V21        = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	Postman_Variable_Base_Url = "baseUrl"
+	Postman_Variable_Proxy    = "proxy"
+
+	Postman_Event_Prerequest = "prerequest"
+	Postman_Event_Test       = "test"
+
+	Postman_Body_Mode_Raw        = "raw"
+	Postman_Body_Mode_Urlencoded = "urlencoded"
+	Postman_Body_Mode_Formdata   = "formdata"
+)
+
+// PostmanCollection postman v2.1 集合
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Event    []PostmanEvent    `json:"event,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string `json:"_postman_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+	Version     string `json:"version,omitempty"`
+}
+
+// PostmanItem 有 Item 时为文件夹,有 Request 时为请求
+type PostmanItem struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Item        []PostmanItem     `json:"item,omitempty"`
+	Request     *PostmanRequest   `json:"request,omitempty"`
+	Response    []PostmanResponse `json:"response,omitempty"`
+	Event       []PostmanEvent    `json:"event,omitempty"`
+	Variable    []PostmanVariable `json:"variable,omitempty"`
+}
+
+func (item PostmanItem) IsFolder() bool {
+	return item.Request == nil
+}
+
+type PostmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanKeyValue `json:"header"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	URL         PostmanURL        `json:"url"`
+	Description string            `json:"description,omitempty"`
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []PostmanKeyValue   `json:"urlencoded,omitempty"`
+	FormData   []PostmanKeyValue   `json:"formdata,omitempty"`
+	Options    *PostmanBodyOptions `json:"options,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type PostmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type PostmanResponse struct {
+	Name                   string            `json:"name"`
+	OriginalRequest        *PostmanRequest   `json:"originalRequest,omitempty"`
+	Status                 string            `json:"status,omitempty"`
+	Code                   int               `json:"code,omitempty"`
+	PostmanPreviewLanguage string            `json:"_postman_previewlanguage,omitempty"`
+	Header                 []PostmanKeyValue `json:"header,omitempty"`
+	Body                   string            `json:"body,omitempty"`
+}
+
+type PostmanEvent struct {
+	Listen string        `json:"listen"`
+	Script PostmanScript `json:"script"`
+}
+
+type PostmanScript struct {
+	Type string   `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+type PostmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PostmanEnvironment postman 环境,由 Service.Servers 生成
+type PostmanEnvironment struct {
+	Name                 string                    `json:"name"`
+	Values               []PostmanEnvironmentValue `json:"values"`
+	PostmanVariableScope string                    `json:"_postman_variable_scope"`
+}
+
+type PostmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Service2Postman 导出 postman v2.1 集合,Group 为文件夹,Examples 为保存的响应,Servers 为环境
+func Service2Postman(service Service) (collection *PostmanCollection, environments []PostmanEnvironment, err error) {
+	collection = &PostmanCollection{
+		Info: PostmanInfo{
+			Name:        service.TitleOrDescription(),
+			Description: service.Description,
+			Schema:      Postman_Schema_V21,
+			Version:     service.Version,
+		},
+		Item: make([]PostmanItem, 0),
+	}
+	if collection.Info.Name == "" {
+		collection.Info.Name = service.Name
+	}
+	hasBaseUrl := false
+	for _, variable := range service.Variables {
+		if variable.Name == Postman_Variable_Base_Url {
+			hasBaseUrl = true
+		}
+		collection.Variable = append(collection.Variable, PostmanVariable{
+			Key:         variable.Name,
+			Value:       variable.Value,
+			Type:        "string",
+			Description: variable.Description,
+		})
+	}
+	if !hasBaseUrl { // 未选择环境时默认使用第一个服务器
+		collection.Variable = append(collection.Variable, PostmanVariable{
+			Key:   Postman_Variable_Base_Url,
+			Value: service.Servers.GetDefault().URL,
+			Type:  "string",
+		})
+	}
+	collection.Event = append(collection.Event, postmanEvents(service.RequestPreScript, service.RequestPostScript)...)
+
+	for _, group := range service.Apis.GetGroups() {
+		items := make([]PostmanItem, 0)
+		for _, api := range service.Apis.GetByGroups(group) {
+			item, err := Api2PostmanItem(api)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		if group == "" {
+			collection.Item = append(collection.Item, items...)
+			continue
+		}
+		collection.Item = append(collection.Item, PostmanItem{Name: group, Item: items})
+	}
+	environments = Servers2PostmanEnvironments(service.Servers)
+	return collection, environments, nil
+}
+
+// Service2PostmanJson 导出 postman 集合json
+func Service2PostmanJson(service Service) (out []byte, err error) {
+	collection, _, err := Service2Postman(service)
+	if err != nil {
+		return nil, err
+	}
+	out, err = json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Servers2PostmanEnvironments 每个服务器生成一个环境,服务器地址对应 {{baseUrl}} 变量
+func Servers2PostmanEnvironments(servers Servers) (environments []PostmanEnvironment) {
+	environments = make([]PostmanEnvironment, 0, len(servers))
+	for _, server := range servers {
+		name := server.Name
+		if server.Title != "" {
+			name = fmt.Sprintf("%s(%s)", server.Name, server.Title)
+		}
+		environment := PostmanEnvironment{
+			Name:                 name,
+			PostmanVariableScope: "environment",
+			Values: []PostmanEnvironmentValue{
+				{Key: Postman_Variable_Base_Url, Value: server.URL, Type: "default", Enabled: true},
+			},
+		}
+		if server.Proxy != "" {
+			environment.Values = append(environment.Values, PostmanEnvironmentValue{Key: Postman_Variable_Proxy, Value: server.Proxy, Type: "default", Enabled: true})
+		}
+		for _, variable := range server.Variables {
+			valueType := "default"
+			if variable.Env != "" {
+				valueType = "secret"
+			}
+			environment.Values = append(environment.Values, PostmanEnvironmentValue{Key: variable.Name, Value: variable.Value, Type: valueType, Enabled: true})
+		}
+		environments = append(environments, environment)
+	}
+	return environments
+}
+
+// Api2PostmanItem 单个接口转换为 postman 请求
+func Api2PostmanItem(api Api) (item PostmanItem, err error) {
+	item = PostmanItem{
+		Name:        api.TitleOrDescription(),
+		Description: api.Description,
+	}
+	if item.Name == "" {
+		item.Name = api.Name
+	}
+	request, err := api.postmanRequest()
+	if err != nil {
+		return item, err
+	}
+	item.Request = request
+
+	preScripts, postScripts := Scripts{}, Scripts{}
+	for i, example := range api.Examples {
+		if example == nil {
+			continue
+		}
+		preScripts.Add(example.RequestPreScript...)
+		postScripts.Add(example.RequestPostScript...)
+		item.Response = append(item.Response, api.postmanResponse(*example, i))
+	}
+	item.Event = postmanEvents(uniqueScripts(preScripts), uniqueScripts(postScripts))
+	return item, nil
+}
+
+func (api Api) postmanRequest() (request *PostmanRequest, err error) {
+	request = &PostmanRequest{
+		Method:      strings.ToUpper(api.Method),
+		Header:      make([]PostmanKeyValue, 0),
+		Description: api.Description,
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	request.URL = postmanURL(api.Path, api.Query)
+	for _, h := range api.RequestHeader {
+		request.Header = append(request.Header, PostmanKeyValue{Key: h.Name, Value: h.Value(), Description: h.TitleOrDescription()})
+	}
+	if api.RequestContentType != "" && api.RequestHeader.ContentType() == "" {
+		request.Header = append(request.Header, PostmanKeyValue{Key: HEADER_NAME_CONTENT_TYPE, Value: api.RequestContentType})
+	}
+	if len(api.RequestBody) == 0 {
+		return request, nil
+	}
+
+	contentType := strings.ToLower(api.RequestContentType)
+	switch {
+	case strings.Contains(contentType, "x-www-form-urlencoded"), strings.Contains(contentType, "form-data"):
+		kvs := make([]PostmanKeyValue, 0)
+		for _, p := range api.RequestBody {
+			p.FormatField()
+			kvs = append(kvs, PostmanKeyValue{Key: p.Fullname, Value: p.Value(), Description: p.TitleOrDescription(), Type: "text"})
+		}
+		request.Body = &PostmanBody{Mode: Postman_Body_Mode_Urlencoded, URLEncoded: kvs}
+		if strings.Contains(contentType, "form-data") {
+			request.Body = &PostmanBody{Mode: Postman_Body_Mode_Formdata, FormData: kvs}
+		}
+	default:
+		raw := api.GetFirstExample().RequestBody
+		if raw == "" {
+			raw, err = api.RequestBody.Json(false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		request.Body = postmanRawBody(raw, api.RequestContentType)
+	}
+	return request, nil
+}
+
+func (api Api) postmanResponse(example Example, index int) (response PostmanResponse) {
+	name := example.Title
+	if name == "" {
+		name = fmt.Sprintf("example%d", index+1)
+	}
+	method := example.Method
+	if method == "" {
+		method = api.Method
+	}
+	originalRequest := &PostmanRequest{
+		Method: strings.ToUpper(method),
+		Header: make([]PostmanKeyValue, 0),
+		URL:    postmanExampleURL(example.URL, api),
+	}
+	for _, key := range sortedKeys(example.Headers) {
+		originalRequest.Header = append(originalRequest.Header, PostmanKeyValue{Key: key, Value: example.Headers[key]})
+	}
+	if example.RequestBody != "" {
+		contentType := example.ContentType
+		if contentType == "" {
+			contentType = api.RequestContentType
+		}
+		originalRequest.Body = postmanRawBody(example.RequestBody, contentType)
+	}
+	response = PostmanResponse{
+		Name:            name,
+		OriginalRequest: originalRequest,
+		Status:          "OK",
+		Code:            200,
+		Body:            example.Response,
+	}
+	if api.ResponseContentType != "" {
+		response.Header = append(response.Header, PostmanKeyValue{Key: HEADER_NAME_CONTENT_TYPE, Value: api.ResponseContentType})
+	}
+	response.PostmanPreviewLanguage = "text"
+	if api.IsResponseContentTypeJson() || json.Valid([]byte(example.Response)) {
+		response.PostmanPreviewLanguage = "json"
+	}
+	return response
+}
+
+func postmanRawBody(raw string, contentType string) (body *PostmanBody) {
+	body = &PostmanBody{Mode: Postman_Body_Mode_Raw, Raw: raw}
+	if contentType == "" || strings.Contains(strings.ToLower(contentType), "json") {
+		body.Options = &PostmanBodyOptions{}
+		body.Options.Raw.Language = "json"
+	}
+	return body
+}
+
+// postmanURL 使用 {{baseUrl}} 作为主机,路径参数转换为 :name 格式
+func postmanURL(path string, query Query) (u PostmanURL) {
+	u = PostmanURL{Host: []string{fmt.Sprintf("{{%s}}", Postman_Variable_Base_Url)}}
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(strings.Trim(openAPIPath(path), "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.Trim(segment, "{}")
+			segment = ":" + name
+			u.Variable = append(u.Variable, PostmanKeyValue{Key: name})
+		}
+		segments = append(segments, segment)
+	}
+	u.Path = segments
+	rawQuery := make([]string, 0)
+	ps := Parameters(query)
+	ps.FormatField()
+	for _, q := range ps {
+		if strings.EqualFold(q.Position, OpenAPI_In_Path) {
+			for i := range u.Variable {
+				if u.Variable[i].Key == q.Name {
+					u.Variable[i].Value, u.Variable[i].Description = q.Value(), q.TitleOrDescription()
+				}
+			}
+			continue
+		}
+		u.Query = append(u.Query, PostmanKeyValue{Key: q.Name, Value: q.Value(), Description: q.TitleOrDescription()})
+		rawQuery = append(rawQuery, fmt.Sprintf("%s=%s", q.Name, q.Value()))
+	}
+	u.Raw = fmt.Sprintf("%s/%s", u.Host[0], strings.Join(segments, "/"))
+	if len(rawQuery) > 0 {
+		u.Raw = fmt.Sprintf("%s?%s", u.Raw, strings.Join(rawQuery, "&"))
+	}
+	return u
+}
+
+// postmanExampleURL 案例中的完整地址保留,相对路径挂到 {{baseUrl}} 下
+func postmanExampleURL(exampleURL string, api Api) (u PostmanURL) {
+	if exampleURL == "" {
+		return postmanURL(api.Path, api.Query)
+	}
+	if strings.HasPrefix(exampleURL, "http") {
+		u = PostmanURL{Raw: exampleURL}
+		if parsed, err := url.Parse(exampleURL); err == nil {
+			u.Host = strings.Split(parsed.Host, ".")
+			u.Path = strings.Split(strings.Trim(parsed.Path, "/"), "/")
+			query := parsed.Query()
+			for _, key := range sortedKeys(query) {
+				for _, value := range query[key] {
+					u.Query = append(u.Query, PostmanKeyValue{Key: key, Value: value})
+				}
+			}
+		}
+		return u
+	}
+	path, rawQuery, _ := strings.Cut(exampleURL, "?")
+	u = postmanURL(path, nil)
+	if rawQuery != "" {
+		u.Raw = fmt.Sprintf("%s?%s", u.Raw, rawQuery)
+		values, _ := url.ParseQuery(rawQuery)
+		for _, key := range sortedKeys(values) {
+			for _, value := range values[key] {
+				u.Query = append(u.Query, PostmanKeyValue{Key: key, Value: value})
+			}
+		}
+	}
+	return u
+}
+
+// postmanEvents 只导出语言为 javascript(含别名)的脚本
+func postmanEvents(preScripts Scripts, postScripts Scripts) (events []PostmanEvent) {
+	if script := preScripts.FilterByLanguage(LANGUAGE_JAVASCRIPT); len(script) > 0 {
+		events = append(events, PostmanEvent{Listen: Postman_Event_Prerequest, Script: postmanScript(script)})
+	}
+	if script := postScripts.FilterByLanguage(LANGUAGE_JAVASCRIPT); len(script) > 0 {
+		events = append(events, PostmanEvent{Listen: Postman_Event_Test, Script: postmanScript(script)})
+	}
+	return events
+}
+
+func postmanScript(scripts Scripts) (script PostmanScript) {
+	return PostmanScript{
+		Type: "text/javascript",
+		Exec: strings.Split(strings.TrimRight(scripts.String(), "\n"), "\n"),
+	}
+}
+
+func uniqueScripts(scripts Scripts) (unique Scripts) {
+	unique = make(Scripts, 0)
+	for _, script := range scripts {
+		exists := false
+		for _, u := range unique {
+			if u.IsEqual(script) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			unique = append(unique, script)
+		}
+	}
+	return unique
+}
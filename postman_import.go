@@ -0,0 +1,380 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+var ERROR_POSTMAN_COLLECTION = errors.New("invalid postman collection")
+
+// Postman2Service 导入 postman v2.1(兼容 v2.0) 集合,文件夹为 Group,保存的响应为 Example,集合变量为 Service.Variables
+func Postman2Service(data []byte) (service *Service, warnings ImportWarnings, err error) {
+	var raw any
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		err = errors.WithMessage(err, "parse postman collection")
+		return nil, nil, err
+	}
+	rawMap, ok := normalizePostmanValue(raw, "").(map[string]any)
+	if !ok {
+		err = errors.WithMessage(ERROR_POSTMAN_COLLECTION, "collection must be an object")
+		return nil, nil, err
+	}
+	var collection PostmanCollection
+	err = convertByJson(rawMap, &collection)
+	if err != nil {
+		err = errors.WithMessage(err, "parse postman collection")
+		return nil, nil, err
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.") {
+		err = errors.WithMessagef(ERROR_POSTMAN_COLLECTION, "unsupported schema:%s", collection.Info.Schema)
+		return nil, nil, err
+	}
+	if _, ok := rawMap["auth"]; ok {
+		warnings.Add("collection", "auth is not supported")
+	}
+
+	importer := &postmanImporter{warnings: &warnings, hostVariables: make(map[string]bool)}
+	service = &Service{
+		Name:        collection.Info.Name,
+		Title:       collection.Info.Name,
+		Description: collection.Info.Description,
+		Version:     collection.Info.Version,
+		Servers:     make(Servers, 0),
+	}
+	service.RequestPreScript, service.RequestPostScript = importer.scripts(collection.Event, "collection")
+
+	apis := importer.items(collection.Item, "")
+	for _, variable := range collection.Variable {
+		service.AddVariable(Variable{Name: variable.Key, Value: variable.Value, Description: variable.Description})
+		if importer.hostVariables[variable.Key] && variable.Value != "" { // {{baseUrl}} 之类的主机变量同时作为服务器
+			service.Servers = append(service.Servers, Server{Name: variable.Key, URL: variable.Value})
+		}
+	}
+	for _, host := range importer.hosts {
+		if _, exists := service.Servers.GetByName(host); !exists {
+			service.Servers = append(service.Servers, Server{Name: host, URL: host})
+		}
+	}
+	service.AddApi(apis...)
+	return service, warnings, nil
+}
+
+type postmanImporter struct {
+	warnings      *ImportWarnings
+	hosts         []string
+	hostVariables map[string]bool
+}
+
+func (importer *postmanImporter) items(items []PostmanItem, group string) (apis Apis) {
+	apis = make(Apis, 0)
+	for _, item := range items {
+		if item.IsFolder() {
+			subGroup := item.Name
+			if group != "" {
+				subGroup = fmt.Sprintf("%s/%s", group, item.Name)
+			}
+			if len(item.Event) > 0 {
+				importer.warnings.Add(subGroup, "folder scripts are not supported")
+			}
+			apis = append(apis, importer.items(item.Item, subGroup)...)
+			continue
+		}
+		apis = append(apis, importer.api(item, group))
+	}
+	return apis
+}
+
+func (importer *postmanImporter) api(item PostmanItem, group string) (api Api) {
+	request := item.Request
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = "GET"
+	}
+	location := item.Name
+	if group != "" {
+		location = fmt.Sprintf("%s/%s", group, item.Name)
+	}
+	path, query, pathVariables := importer.url(request.URL)
+	api = Api{
+		Group:       group,
+		Name:        makeApiName(method, path),
+		Title:       item.Name,
+		Description: request.Description,
+		Method:      method,
+		Path:        path,
+	}
+	if api.Description == "" {
+		api.Description = item.Description
+	}
+	for _, v := range pathVariables {
+		p := Value2Parameter(v.Key, v.Value)
+		p.Description, p.Position, p.Required = v.Description, OpenAPI_In_Path, true
+		api.Query.Add(p)
+	}
+	for _, q := range query {
+		p := Value2Parameter(q.Key, q.Value)
+		p.Description = q.Description
+		api.Query.Add(p)
+	}
+	for _, h := range request.Header {
+		if strings.EqualFold(h.Key, HEADER_NAME_CONTENT_TYPE) {
+			api.RequestContentType = h.Value
+		}
+		p := Parameter{Fullname: h.Key, Type: OpenAPI_Type_String, Example: h.Value, Description: h.Description, Position: PARAMETER_ATTR_POSITION_ENUM_HEADER}
+		api.RequestHeader.Add(p)
+	}
+	api.RequestBody = importer.body(request.Body, &api.RequestContentType, location)
+
+	for _, response := range item.Response {
+		example := &Example{
+			Title:    response.Name,
+			Method:   method,
+			URL:      api.Path,
+			Response: response.Body,
+		}
+		if original := response.OriginalRequest; original != nil {
+			if original.Method != "" {
+				example.Method = strings.ToUpper(original.Method)
+			}
+			if original.URL.Raw != "" {
+				example.URL = original.URL.Raw
+			}
+			for _, h := range original.Header {
+				if example.Headers == nil {
+					example.Headers = make(map[string]string)
+				}
+				example.Headers[h.Key] = h.Value
+				if strings.EqualFold(h.Key, HEADER_NAME_CONTENT_TYPE) {
+					example.ContentType = h.Value
+				}
+			}
+			if original.Body != nil {
+				example.RequestBody = postmanBodyString(*original.Body)
+			}
+		}
+		if example.ContentType == "" {
+			example.ContentType = api.RequestContentType
+		}
+		if response.Code != 0 && (response.Code < 200 || response.Code >= 300) {
+			example.Tag = cast.ToString(response.Code) // 非2xx响应用状态码作为标签,便于 mock 区分场景
+		}
+		for _, h := range response.Header {
+			if strings.EqualFold(h.Key, HEADER_NAME_CONTENT_TYPE) && api.ResponseContentType == "" {
+				api.ResponseContentType = h.Value
+			}
+		}
+		isSuccess := response.Code == 0 || (response.Code >= 200 && response.Code < 300)
+		if isSuccess && response.Body != "" && json.Valid([]byte(response.Body)) {
+			params, err := Json2Parameters(response.Body)
+			if err == nil {
+				api.ResponseBody = api.ResponseBody.MergeInferred(params...)
+			}
+		}
+		api.Examples = append(api.Examples, example)
+	}
+
+	preScripts, postScripts := importer.scripts(item.Event, location)
+	if len(preScripts) > 0 || len(postScripts) > 0 {
+		if len(api.Examples) == 0 {
+			importer.warnings.Add(location, "request scripts are ignored because the request has no saved response")
+		}
+		for _, example := range api.Examples {
+			example.RequestPreScript, example.RequestPostScript = preScripts, postScripts
+		}
+	}
+	return api
+}
+
+var postmanVariableReg = regexp.MustCompile(`^\{\{([^}]+)\}\}`)
+
+// url 解析路径、query和路径参数,主机记录为服务器
+func (importer *postmanImporter) url(u PostmanURL) (path string, query []PostmanKeyValue, pathVariables []PostmanKeyValue) {
+	host := strings.Join(u.Host, ".")
+	segments := u.Path
+	query = u.Query
+	if len(u.Host) == 0 && len(u.Path) == 0 && u.Raw != "" { // 只有raw 时自行解析
+		raw := u.Raw
+		if match := postmanVariableReg.FindString(raw); match != "" {
+			host, raw = match, strings.TrimPrefix(raw, match)
+		} else if parsed, err := url.Parse(raw); err == nil && parsed.Host != "" {
+			host, raw = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), parsed.RequestURI()
+		}
+		rawPath, rawQuery, _ := strings.Cut(raw, "?")
+		segments = strings.Split(strings.Trim(rawPath, "/"), "/")
+		values, _ := url.ParseQuery(rawQuery)
+		for _, key := range sortedKeys(values) {
+			for _, value := range values[key] {
+				query = append(query, PostmanKeyValue{Key: key, Value: value})
+			}
+		}
+	} else if host != "" && !postmanVariableReg.MatchString(host) && u.Raw != "" {
+		if parsed, err := url.Parse(u.Raw); err == nil && parsed.Host != "" {
+			host = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+		}
+	}
+	if match := postmanVariableReg.FindStringSubmatch(host); match != nil {
+		importer.hostVariables[match[1]] = true
+	} else if host != "" && !containsString(importer.hosts, host) {
+		importer.hosts = append(importer.hosts, host)
+	}
+
+	pathSegments := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") {
+			name := strings.TrimPrefix(segment, ":")
+			segment = fmt.Sprintf("{%s}", name)
+			variable := PostmanKeyValue{Key: name}
+			for _, v := range u.Variable {
+				if v.Key == name {
+					variable = v
+				}
+			}
+			pathVariables = append(pathVariables, variable)
+		}
+		pathSegments = append(pathSegments, segment)
+	}
+	path = "/" + strings.Join(pathSegments, "/")
+	return path, query, pathVariables
+}
+
+func (importer *postmanImporter) body(body *PostmanBody, contentType *string, location string) (params Parameters) {
+	params = make(Parameters, 0)
+	if body == nil {
+		return params
+	}
+	switch body.Mode {
+	case Postman_Body_Mode_Raw:
+		raw := strings.TrimSpace(body.Raw)
+		if raw == "" {
+			return params
+		}
+		isJson := body.Options != nil && body.Options.Raw.Language == "json" || strings.Contains(strings.ToLower(*contentType), "json")
+		inferred, err := Json2Parameters(raw)
+		if err != nil {
+			if isJson {
+				importer.warnings.Add(location, "raw json body can not be parsed: %s", err.Error())
+			} else {
+				importer.warnings.Add(location, "raw body is not json, parameters are not inferred")
+			}
+			return params
+		}
+		if *contentType == "" {
+			*contentType = Header_Value_Content_Type_Json
+		}
+		return inferred
+	case Postman_Body_Mode_Urlencoded, Postman_Body_Mode_Formdata:
+		kvs := body.URLEncoded
+		defaultContentType := "application/x-www-form-urlencoded"
+		if body.Mode == Postman_Body_Mode_Formdata {
+			kvs, defaultContentType = body.FormData, "multipart/form-data"
+		}
+		if *contentType == "" {
+			*contentType = defaultContentType
+		}
+		for _, kv := range kvs {
+			p := Value2Parameter(kv.Key, kv.Value)
+			p.Description = kv.Description
+			if kv.Type == "file" {
+				p.Type = OpenAPI_Type_String
+				p.SetFormat("file")
+			}
+			params.Add(p)
+		}
+		return params
+	case "":
+		return params
+	}
+	importer.warnings.Add(location, "body mode %q is not supported", body.Mode)
+	return params
+}
+
+func (importer *postmanImporter) scripts(events []PostmanEvent, location string) (preScripts Scripts, postScripts Scripts) {
+	for _, event := range events {
+		text := strings.Join(event.Script.Exec, "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		script := Script{Language: LANGUAGE_JAVASCRIPT, Text: text}
+		switch event.Listen {
+		case Postman_Event_Prerequest:
+			preScripts.Add(script)
+		case Postman_Event_Test:
+			postScripts.Add(script)
+		default:
+			importer.warnings.Add(location, "script event %q is not supported", event.Listen)
+		}
+	}
+	return preScripts, postScripts
+}
+
+func postmanBodyString(body PostmanBody) (s string) {
+	switch body.Mode {
+	case Postman_Body_Mode_Raw:
+		return body.Raw
+	case Postman_Body_Mode_Urlencoded, Postman_Body_Mode_Formdata:
+		kvs := body.URLEncoded
+		if body.Mode == Postman_Body_Mode_Formdata {
+			kvs = body.FormData
+		}
+		values := url.Values{}
+		for _, kv := range kvs {
+			values.Add(kv.Key, kv.Value)
+		}
+		return values.Encode()
+	}
+	return ""
+}
+
+// normalizePostmanValue 统一 postman 中同一字段的多种写法(字符串/对象),便于按结构体解析
+func normalizePostmanValue(v any, key string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		if key == "description" { // {"content":"...","type":"text/markdown"}
+			return cast.ToString(val["content"])
+		}
+		for k, child := range val {
+			val[k] = normalizePostmanValue(child, k)
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = normalizePostmanValue(child, "")
+		}
+		return val
+	case string:
+		switch key {
+		case "url":
+			return map[string]any{"raw": val}
+		case "exec":
+			return []any{val}
+		case "request":
+			return map[string]any{"method": "GET", "url": map[string]any{"raw": val}}
+		case "header":
+			headers := make([]any, 0)
+			for _, line := range strings.Split(val, "\n") {
+				k, value, ok := strings.Cut(line, ":")
+				if ok {
+					headers = append(headers, map[string]any{"key": strings.TrimSpace(k), "value": strings.TrimSpace(value)})
+				}
+			}
+			return headers
+		}
+		return val
+	case nil:
+		return val
+	}
+	if key == "value" { // 变量值可能是数字、布尔值
+		return cast.ToString(v)
+	}
+	return v
+}
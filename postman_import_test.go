@@ -0,0 +1,89 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+const postmanCollectionJson = `{
+	"info": {"name": "shop", "description": {"content": "商城接口", "type": "text/markdown"}, "schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"},
+	"event": [{"listen": "prerequest", "script": {"type": "text/javascript", "exec": "pm.variables.set('ts', Date.now())"}}],
+	"variable": [{"key": "host", "value": "https://shop.api.com"}, {"key": "pageSize", "value": 10}],
+	"item": [{
+		"name": "订单",
+		"item": [{
+			"name": "后台",
+			"item": [{
+				"name": "订单详情",
+				"request": {
+					"method": "post",
+					"header": "Content-Type: application/json\nX-Token: abc",
+					"url": "{{host}}/order/:orderId?withItems=true",
+					"body": {"mode": "raw", "raw": "{\"remark\":\"加急\",\"amount\":1.5}"}
+				},
+				"event": [{"listen": "test", "script": {"exec": ["pm.response.to.have.status(200)"]}}],
+				"response": [
+					{"name": "成功", "code": 200, "header": [{"key": "Content-Type", "value": "application/json"}], "body": "{\"code\":0,\"data\":{\"id\":7,\"items\":[{\"sku\":\"A1\"},{\"sku\":\"B2\",\"count\":2}]}}"},
+					{"name": "不存在", "code": 404, "body": "{\"code\":404,\"data\":null}"}
+				]
+			}]
+		}]
+	}, {
+		"name": "登录",
+		"request": {
+			"method": "POST",
+			"url": {"raw": "https://auth.api.com/login", "host": ["auth", "api", "com"], "path": ["login"]},
+			"body": {"mode": "urlencoded", "urlencoded": [{"key": "name", "value": "tom"}, {"key": "age", "value": "18"}]}
+		}
+	}]
+}`
+
+func TestPostman2Service(t *testing.T) {
+	service, warnings, err := apidocbuilder.Postman2Service([]byte(postmanCollectionJson))
+	require.NoError(t, err)
+	require.Equal(t, "商城接口", service.Description)
+	require.Equal(t, []string{"pm.variables.set('ts', Date.now())"}, []string{service.RequestPreScript[0].Text})
+	require.Equal(t, "10", service.Variables[1].Value)
+	require.Len(t, service.Servers, 2)
+	require.Equal(t, "https://shop.api.com", service.Servers[0].URL)
+	require.Equal(t, "https://auth.api.com", service.Servers[1].URL)
+
+	api, err := service.GetApi("POST", "/order/{orderId}")
+	require.NoError(t, err)
+	require.Equal(t, "订单/后台", api.Group)
+	require.Equal(t, "postOrderOrderId", api.Name)
+	require.Equal(t, "application/json", api.RequestContentType)
+	require.Equal(t, "path", api.Query[0].Position)
+	require.Equal(t, "boolean", api.Query[1].Type)
+	require.Len(t, api.RequestHeader, 2)
+	require.Equal(t, "number", api.RequestBody[0].Type)
+
+	names := make([]string, 0)
+	for _, p := range api.ResponseBody {
+		names = append(names, p.Fullname+":"+p.Type)
+	}
+	require.Equal(t, []string{"code:int", "data.id:int", "data.items[].sku:string", "data.items[].count:int"}, names)
+	require.Len(t, api.Examples, 2)
+	require.Equal(t, "404", api.Examples[1].Tag)
+	require.Equal(t, "pm.response.to.have.status(200)", api.Examples[0].RequestPostScript[0].Text)
+
+	login, err := service.GetApi("POST", "/login")
+	require.NoError(t, err)
+	require.Equal(t, "application/x-www-form-urlencoded", login.RequestContentType)
+	require.Equal(t, "int", login.RequestBody[1].Type)
+	require.Empty(t, warnings)
+}
+
+func TestPostman2ServiceRoundTrip(t *testing.T) {
+	b, err := apidocbuilder.Service2PostmanJson(openAPIService)
+	require.NoError(t, err)
+	service, _, err := apidocbuilder.Postman2Service(b)
+	require.NoError(t, err)
+	api, err := service.GetApi("POST", "/user/{id}/list")
+	require.NoError(t, err)
+	require.Equal(t, "user", api.Group)
+	require.Len(t, api.Examples, 1)
+	require.Equal(t, "http://dev.api.com", service.Servers[0].URL)
+}
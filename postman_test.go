@@ -0,0 +1,52 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+	"github.com/tidwall/gjson"
+)
+
+func TestService2Postman(t *testing.T) {
+	service := openAPIService
+	service.Variables = apidocbuilder.Variables{{Name: "token", Value: "abc"}}
+	service.RequestPreScript = apidocbuilder.Scripts{
+		{Language: "js", Text: "pm.variables.set('ts', Date.now())"},
+		{Language: "tengo", Text: "ts := 1"},
+	}
+	collection, environments, err := apidocbuilder.Service2Postman(service)
+	require.NoError(t, err)
+	require.Len(t, environments, 1)
+	require.Equal(t, "http://dev.api.com", environments[0].Values[0].Value)
+
+	require.Len(t, collection.Event, 1)
+	require.Equal(t, "prerequest", collection.Event[0].Listen)
+	require.Equal(t, []string{"pm.variables.set('ts', Date.now())"}, collection.Event[0].Script.Exec)
+
+	folder := collection.Item[0]
+	require.True(t, folder.IsFolder())
+	require.Equal(t, "user", folder.Name)
+	request := folder.Item[0].Request
+	require.Equal(t, "POST", request.Method)
+	require.Equal(t, "{{baseUrl}}/user/:id/list?ids=", request.URL.Raw)
+	require.Equal(t, "raw", request.Body.Mode)
+	require.Len(t, folder.Item[0].Response, 1)
+
+	b, err := apidocbuilder.Service2PostmanJson(service)
+	require.NoError(t, err)
+	require.Equal(t, "abc", gjson.GetBytes(b, `variable.#(key=="token").value`).String())
+}
+
+func TestApi2PostmanItemExampleQueryOrder(t *testing.T) {
+	api := apidocbuilder.Api{Name: "list", Method: "GET", Path: "/list", Examples: []*apidocbuilder.Example{
+		{URL: "http://api.com/list?z=1&b=2&m=3&a=4&b=5"},
+	}}
+	item, err := apidocbuilder.Api2PostmanItem(api)
+	require.NoError(t, err)
+	keys := make([]string, 0)
+	for _, kv := range item.Response[0].OriginalRequest.URL.Query {
+		keys = append(keys, kv.Key+"="+kv.Value)
+	}
+	require.Equal(t, []string{"a=4", "b=2", "b=5", "m=3", "z=1"}, keys)
+}
@@ -0,0 +1,200 @@
+package apidocbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/d5/tengo/v2"
+	"github.com/d5/tengo/v2/stdlib"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// ScriptModulesDefault 脚本可导入的标准库,不包含 os 等可访问宿主环境的模块
+var ScriptModulesDefault = []string{"math", "text", "times", "rand", "fmt", "json", "base64", "hex", "enum"}
+
+// ScriptRuntime 执行 tengo 脚本,限制执行时间和内存分配次数,防止脚本死循环、耗尽资源
+type ScriptRuntime struct {
+	// 单个脚本最长执行时间
+	Timeout time.Duration
+	// 单个脚本最多分配的对象数,超过后中止执行
+	MaxAllocs int64
+	// 可导入的标准库
+	Modules []string
+}
+
+func NewScriptRuntime() (runtime *ScriptRuntime) {
+	return &ScriptRuntime{Timeout: time.Second, MaxAllocs: 100000, Modules: ScriptModulesDefault}
+}
+
+// ScriptRequest 前置脚本中为 request 变量,可修改 method、url、headers、body
+type ScriptRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// ScriptResponse 后置脚本、测试脚本中为 response 变量,json 为响应体解析后的值
+type ScriptResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// ScriptContext 脚本执行上下文,多个脚本、多个请求之间共享 Variables(如登录后保存token)
+type ScriptContext struct {
+	Request   ScriptRequest
+	Response  *ScriptResponse
+	Variables map[string]string
+}
+
+type ScriptAssertion struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+type ScriptAssertions []ScriptAssertion
+
+func (as ScriptAssertions) Failed() (failed ScriptAssertions) {
+	for _, a := range as {
+		if !a.Passed {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+type ScriptResult struct {
+	Assertions ScriptAssertions `json:"assertions"`
+	Logs       []string         `json:"logs"`
+}
+
+// RunPre 执行前置脚本,脚本对 request、variables 的修改写回 sc
+func (rt *ScriptRuntime) RunPre(ctx context.Context, scripts Scripts, sc *ScriptContext) (result ScriptResult, err error) {
+	return rt.runScripts(ctx, scripts, sc)
+}
+
+// RunPost 执行后置脚本和测试脚本,断言结果记录在 result 中,断言失败不返回错误
+func (rt *ScriptRuntime) RunPost(ctx context.Context, scripts Scripts, testScript string, sc *ScriptContext) (result ScriptResult, err error) {
+	if strings.TrimSpace(testScript) != "" {
+		scripts = append(append(Scripts{}, scripts...), Script{Language: LANGUAGE_TENGO, Text: testScript})
+	}
+	return rt.runScripts(ctx, scripts, sc)
+}
+
+func (rt *ScriptRuntime) runScripts(ctx context.Context, scripts Scripts, sc *ScriptContext) (result ScriptResult, err error) {
+	if sc.Variables == nil {
+		sc.Variables = make(map[string]string)
+	}
+	for _, script := range scripts.FilterByLanguage(LANGUAGE_TENGO) {
+		err = rt.Run(ctx, script.Text, sc, &result)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Run 执行单个脚本,可使用的变量:request、response(后置脚本)、variables,函数:assert(cond, name)、log(args...)
+func (rt *ScriptRuntime) Run(ctx context.Context, src string, sc *ScriptContext, result *ScriptResult) (err error) {
+	script := tengo.NewScript([]byte(src))
+	script.SetImports(stdlib.GetModuleMap(rt.Modules...))
+	script.SetMaxAllocs(rt.MaxAllocs)
+	globals := map[string]any{
+		"request":   scriptRequest2Map(sc.Request),
+		"variables": stringMap2Any(sc.Variables),
+	}
+	if sc.Response != nil {
+		globals["response"] = scriptResponse2Map(*sc.Response)
+	}
+	for name, value := range globals {
+		if err = script.Add(name, value); err != nil {
+			return errors.WithMessagef(err, "add script variable %s", name)
+		}
+	}
+	if err = script.Add("assert", &tengo.UserFunction{Name: "assert", Value: result.assert}); err != nil {
+		return err
+	}
+	if err = script.Add("log", &tengo.UserFunction{Name: "log", Value: result.log}); err != nil {
+		return err
+	}
+
+	if rt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rt.Timeout)
+		defer cancel()
+	}
+	compiled, err := script.RunContext(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "run tengo script")
+	}
+
+	request := compiled.Get("request").Map()
+	sc.Request.Method = cast.ToString(request["method"])
+	sc.Request.URL = cast.ToString(request["url"])
+	sc.Request.Body = cast.ToString(request["body"])
+	sc.Request.Headers = cast.ToStringMapString(request["headers"])
+	sc.Variables = cast.ToStringMapString(compiled.Get("variables").Map())
+	return nil
+}
+
+// assert(cond, name, message?) 记录断言结果
+func (result *ScriptResult) assert(args ...tengo.Object) (ret tengo.Object, err error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, tengo.ErrWrongNumArguments
+	}
+	assertion := ScriptAssertion{Name: fmt.Sprintf("assertion %d", len(result.Assertions)+1), Passed: !args[0].IsFalsy()}
+	if len(args) > 1 {
+		assertion.Name, _ = tengo.ToString(args[1])
+	}
+	if len(args) > 2 && !assertion.Passed {
+		assertion.Message, _ = tengo.ToString(args[2])
+	}
+	result.Assertions = append(result.Assertions, assertion)
+	return tengo.FromInterface(assertion.Passed)
+}
+
+func (result *ScriptResult) log(args ...tengo.Object) (ret tengo.Object, err error) {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		s, _ := tengo.ToString(arg)
+		values = append(values, s)
+	}
+	result.Logs = append(result.Logs, strings.Join(values, " "))
+	return tengo.UndefinedValue, nil
+}
+
+func scriptRequest2Map(request ScriptRequest) map[string]any {
+	return map[string]any{
+		"method":  request.Method,
+		"url":     request.URL,
+		"headers": stringMap2Any(request.Headers),
+		"body":    request.Body,
+	}
+}
+
+func scriptResponse2Map(response ScriptResponse) map[string]any {
+	m := map[string]any{
+		"status":  response.Status,
+		"headers": stringMap2Any(response.Headers),
+		"body":    response.Body,
+	}
+	var data any
+	if json.Unmarshal([]byte(response.Body), &data) == nil {
+		m["json"] = data
+	}
+	return m
+}
+
+func stringMap2Any(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
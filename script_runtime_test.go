@@ -0,0 +1,113 @@
+package apidocbuilder_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestScriptRuntimePre(t *testing.T) {
+	runtime := apidocbuilder.NewScriptRuntime()
+	sc := &apidocbuilder.ScriptContext{
+		Request:   apidocbuilder.ScriptRequest{Method: "POST", URL: "http://localhost/login", Headers: map[string]string{}, Body: `{"name":"tom"}`},
+		Variables: map[string]string{"token": "abc"},
+	}
+	scripts := apidocbuilder.Scripts{
+		{Language: "javascript", Text: "pm.environment.set('x', 1)"}, // 非 tengo 脚本忽略
+		{Language: "tengo", Text: `
+json := import("json")
+body := json.decode(request.body)
+body.age = 18
+request.body = string(json.encode(body))
+request.headers["Authorization"] = "Bearer " + variables.token
+variables.requestId = "1001"
+log("pre", request.method)
+`},
+	}
+	result, err := runtime.RunPre(context.Background(), scripts, sc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"tom","age":18}`, sc.Request.Body)
+	require.Equal(t, "Bearer abc", sc.Request.Headers["Authorization"])
+	require.Equal(t, "1001", sc.Variables["requestId"])
+	require.Equal(t, []string{"pre POST"}, result.Logs)
+}
+
+func TestScriptRuntimePost(t *testing.T) {
+	runtime := apidocbuilder.NewScriptRuntime()
+	sc := &apidocbuilder.ScriptContext{
+		Response: &apidocbuilder.ScriptResponse{Status: 200, Body: `{"code":0,"data":{"token":"xyz"}}`},
+	}
+	testScript := `
+assert(response.status == 200, "status is 200")
+assert(response.json.code == 1, "code is 1", "got " + response.json.code)
+variables.token = response.json.data.token
+`
+	result, err := runtime.RunPost(context.Background(), nil, testScript, sc)
+	require.NoError(t, err)
+	require.Len(t, result.Assertions, 2)
+	require.True(t, result.Assertions[0].Passed)
+	failed := result.Assertions.Failed()
+	require.Len(t, failed, 1)
+	require.Equal(t, "code is 1", failed[0].Name)
+	require.Equal(t, "got 0", failed[0].Message)
+	require.Equal(t, "xyz", sc.Variables["token"])
+}
+
+func TestScriptRuntimeLimit(t *testing.T) {
+	runtime := apidocbuilder.NewScriptRuntime()
+	runtime.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	_, err := runtime.RunPost(context.Background(), nil, "for {}", &apidocbuilder.ScriptContext{})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), time.Second)
+
+	runtime.MaxAllocs = 100
+	_, err = runtime.RunPost(context.Background(), nil, "a := []\nfor i := 0; i < 1000; i++ { a = append(a, [i]) }", &apidocbuilder.ScriptContext{})
+	require.Error(t, err)
+
+	_, err = runtime.RunPost(context.Background(), nil, `os := import("os")`, &apidocbuilder.ScriptContext{})
+	require.Error(t, err)
+}
+
+func TestContractRunnerScripts(t *testing.T) {
+	service := apidocbuilder.Service{
+		Name:      "user",
+		Variables: apidocbuilder.Variables{{Name: "token", Value: "abc"}},
+		RequestPreScript: apidocbuilder.Scripts{
+			{Language: "tengo", Text: `request.headers["Authorization"] = "Bearer " + variables.token`},
+		},
+		Apis: apidocbuilder.Apis{
+			{
+				Name: "getUser", Method: "GET", Path: "/user/{id}",
+				Examples: apidocbuilder.Examples{
+					{Title: "正常", URL: "/user/1", TestScript: `assert(response.json.name == "tom", "name is tom")`},
+					{Title: "断言失败", URL: "/user/2", TestScript: `assert(response.json.name == "jerry", "name is jerry")`},
+				},
+			},
+		},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"tom"}`))
+	})
+	runner := apidocbuilder.NewContractRunnerWithHandler(service, handler)
+	runner.Scripts = apidocbuilder.NewScriptRuntime()
+	report, err := runner.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	require.True(t, report.Results[0].Passed())
+	require.False(t, report.Results[1].Passed())
+	require.Contains(t, report.Results[1].Failures(), "assert name is jerry")
+
+	md, err := report.Markdown()
+	require.NoError(t, err)
+	require.Contains(t, string(md), "|name is jerry|**失败**||")
+}
@@ -0,0 +1,279 @@
+package apidocbuilder
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 各字段权重,标题、名称、路径命中排在参数、描述命中之前
+const (
+	searchWeightTitle          = 8
+	searchWeightName           = 6
+	searchWeightPath           = 5
+	searchWeightGroup          = 4
+	searchWeightSummary        = 3
+	searchWeightDescription    = 2
+	searchWeightParamFullname  = 3
+	searchWeightParamTitle     = 2
+	searchWeightParamDesc      = 1
+	searchWeightExampleTitle   = 2
+	searchWeightExampleSummary = 1
+
+	// 英文前缀匹配(如 use 匹配 user)的得分折扣
+	searchPrefixDiscount = 0.5
+	// 标题、路径包含完整查询词时的加分
+	searchPhraseBoost = 5
+	searchBm25K1      = 1.2
+)
+
+// SearchResult 搜索结果,Score 越大越相关
+type SearchResult struct {
+	Api   Api
+	Score float64
+}
+
+type SearchResults []SearchResult
+
+func (rs SearchResults) Apis() (apis Apis) {
+	apis = make(Apis, 0, len(rs))
+	for _, r := range rs {
+		apis = append(apis, r.Api)
+	}
+	return apis
+}
+
+// SearchIndex 接口全文索引,索引接口标题、名称、路径、分组、描述,参数名称、标题、描述及案例标题
+type SearchIndex struct {
+	apis  Apis
+	docs  []searchDocument
+	df    map[string]int
+	terms []string // 有序词表,用于前缀匹配
+}
+
+type searchDocument struct {
+	terms map[string]float64 // 词 -> 加权词频
+	text  string             // 标题、名称、路径,用于完整查询词加分
+}
+
+func NewSearchIndex(apis Apis) (index *SearchIndex) {
+	index = &SearchIndex{apis: apis, docs: make([]searchDocument, len(apis)), df: make(map[string]int)}
+	for i, api := range apis {
+		doc := searchDocument{terms: make(map[string]float64), text: strings.ToLower(strings.Join([]string{api.Title, api.Name, api.Path}, " "))}
+		for _, field := range searchFields(api) {
+			for _, term := range SearchTokenize(field.text) {
+				doc.terms[term] += field.weight
+			}
+		}
+		for term := range doc.terms {
+			index.df[term]++
+		}
+		index.docs[i] = doc
+	}
+	index.terms = sortedKeys(index.df)
+	return index
+}
+
+// Search 返回按相关度排序的接口,查询词需全部命中
+func (index *SearchIndex) Search(query string) (apis Apis) {
+	return index.SearchWithScore(query).Apis()
+}
+
+func (index *SearchIndex) SearchWithScore(query string) (results SearchResults) {
+	tokens := searchQueryTokenize(query)
+	if len(tokens) == 0 {
+		return SearchResults{}
+	}
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	results = make(SearchResults, 0)
+	for i, doc := range index.docs {
+		score, matched := 0.0, true
+		for _, token := range tokens {
+			tokenScore := index.tokenScore(doc, token)
+			if tokenScore == 0 {
+				matched = false
+				break
+			}
+			score += tokenScore
+		}
+		if !matched {
+			continue
+		}
+		if strings.Contains(doc.text, phrase) {
+			score += searchPhraseBoost
+		}
+		results = append(results, SearchResult{Api: index.apis[i], Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Api.Name < results[j].Api.Name
+	})
+	return results
+}
+
+// tokenScore 精确命中取 bm25 得分,英文词未精确命中时尝试前缀匹配
+func (index *SearchIndex) tokenScore(doc searchDocument, token string) (score float64) {
+	if tf, ok := doc.terms[token]; ok {
+		return index.bm25(token, tf)
+	}
+	if !isSearchWord(token) {
+		return 0
+	}
+	for i := sort.SearchStrings(index.terms, token); i < len(index.terms) && strings.HasPrefix(index.terms[i], token); i++ {
+		if tf, ok := doc.terms[index.terms[i]]; ok {
+			score = math.Max(score, index.bm25(index.terms[i], tf)*searchPrefixDiscount)
+		}
+	}
+	return score
+}
+
+func (index *SearchIndex) bm25(term string, tf float64) float64 {
+	n, df := float64(len(index.docs)), float64(index.df[term])
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	return idf * tf * (searchBm25K1 + 1) / (tf + searchBm25K1)
+}
+
+// Search 构建临时索引搜索,多次搜索时使用 NewSearchIndex 复用索引
+func (apis Apis) Search(query string) (result Apis) {
+	return NewSearchIndex(apis).Search(query)
+}
+
+type searchField struct {
+	text   string
+	weight float64
+}
+
+func searchFields(api Api) (fields []searchField) {
+	fields = []searchField{
+		{api.Title, searchWeightTitle},
+		{api.Name, searchWeightName},
+		{api.Path, searchWeightPath},
+		{api.Group, searchWeightGroup},
+		{api.Summary, searchWeightSummary},
+		{api.Description, searchWeightDescription},
+	}
+	for _, ps := range []Parameters{Parameters(api.RequestHeader), Parameters(api.Query), api.RequestBody, Parameters(api.ResponseHeader), api.ResponseBody} {
+		for _, p := range ps {
+			fields = append(fields,
+				searchField{p.Fullname, searchWeightParamFullname},
+				searchField{p.Title, searchWeightParamTitle},
+				searchField{p.Description, searchWeightParamDesc},
+			)
+		}
+	}
+	for _, example := range api.Examples {
+		if example == nil {
+			continue
+		}
+		fields = append(fields,
+			searchField{example.Title, searchWeightExampleTitle},
+			searchField{example.Summary, searchWeightExampleSummary},
+		)
+	}
+	return fields
+}
+
+// SearchKeywords 接口被索引的文本(小写),页面中用于无服务端时的即时过滤
+func (api Api) SearchKeywords() (keywords string) {
+	texts := make([]string, 0)
+	for _, field := range searchFields(api) {
+		if field.text != "" {
+			texts = append(texts, field.text)
+		}
+	}
+	return strings.ToLower(strings.Join(texts, " "))
+}
+
+// SearchTokenize 分词:英文数字按分隔符及驼峰拆分并转小写,保留完整单词;中文无需词典,按单字及相邻双字切分
+func SearchTokenize(text string) (tokens []string) {
+	return searchTokenize(text, false)
+}
+
+// searchQueryTokenize 查询中的连续中文只取双字,避免单字匹配过宽
+func searchQueryTokenize(query string) (tokens []string) {
+	tokens = searchTokenize(query, true)
+	unique := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !containsString(unique, token) {
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
+
+func searchTokenize(text string, query bool) (tokens []string) {
+	tokens = make([]string, 0)
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			j := i
+			for j < len(runes) && unicode.Is(unicode.Han, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, hanTokens(runes[i:j], query)...)
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) && !unicode.Is(unicode.Han, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, wordTokens(runes[i:j])...)
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func hanTokens(runes []rune, query bool) (tokens []string) {
+	if len(runes) == 1 {
+		return []string{string(runes)}
+	}
+	for i := range runes {
+		if !query {
+			tokens = append(tokens, string(runes[i]))
+		}
+		if i+1 < len(runes) {
+			tokens = append(tokens, string(runes[i:i+2]))
+		}
+	}
+	return tokens
+}
+
+// wordTokens getUserID2 -> getuserid2 get user id 2
+func wordTokens(runes []rune) (tokens []string) {
+	word := strings.ToLower(string(runes))
+	tokens = append(tokens, word)
+	parts := make([]string, 0)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := (unicode.IsLower(prev) && unicode.IsUpper(cur)) ||
+			(unicode.IsDigit(prev) != unicode.IsDigit(cur)) ||
+			(unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+		if boundary {
+			parts = append(parts, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	if start == 0 {
+		return tokens
+	}
+	parts = append(parts, strings.ToLower(string(runes[start:])))
+	return append(tokens, parts...)
+}
+
+func isSearchWord(token string) bool {
+	for _, r := range token {
+		if unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return len(token) >= 2
+}
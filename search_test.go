@@ -0,0 +1,60 @@
+package apidocbuilder_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestSearchTokenize(t *testing.T) {
+	require.Equal(t, []string{"getuserid2", "get", "user", "id", "2"}, apidocbuilder.SearchTokenize("getUserID2"))
+	require.Equal(t, []string{"用", "用户", "户", "户列", "列", "列表", "表", "user", "list"}, apidocbuilder.SearchTokenize("用户列表 /user/list"))
+}
+
+func TestSearchIndex(t *testing.T) {
+	apis := apidocbuilder.Apis{
+		{Name: "listOrder", Title: "订单列表", Group: "订单", Path: "/order/list", Query: apidocbuilder.Query{{Fullname: "userId", Title: "用户ID"}}},
+		{Name: "getUser", Title: "获取用户", Group: "用户", Path: "/user/get", Description: "根据用户ID查询用户信息"},
+		{Name: "addUser", Title: "新增用户", Group: "用户", Path: "/user/add", RequestBody: apidocbuilder.Parameters{{Fullname: "mobile", Title: "手机号"}}},
+		{Name: "deleteProduct", Title: "删除商品", Path: "/product/delete", Examples: apidocbuilder.Examples{{Title: "删除下架商品"}}},
+	}
+	index := apidocbuilder.NewSearchIndex(apis)
+	names := func(apis apidocbuilder.Apis) (names []string) {
+		for _, api := range apis {
+			names = append(names, api.Name)
+		}
+		return names
+	}
+
+	// 标题命中排在参数命中之前
+	require.Equal(t, []string{"getUser", "addUser", "listOrder"}, names(index.Search("用户")))
+	require.Equal(t, []string{"addUser"}, names(index.Search("手机")))
+	require.Equal(t, []string{"addUser"}, names(index.Search("新增 user")))
+	require.Equal(t, []string{"deleteProduct"}, names(index.Search("下架")))
+	require.Equal(t, []string{"listOrder"}, names(index.Search("userId")))
+	// 英文前缀匹配
+	require.Equal(t, []string{"deleteProduct"}, names(index.Search("prod")))
+	require.Empty(t, index.Search("用户 product"))
+	require.Empty(t, index.Search("  "))
+
+	results := index.SearchWithScore("用户")
+	require.Greater(t, results[0].Score, results[len(results)-1].Score)
+	require.Equal(t, names(index.Search("用户")), names(apis.Search("用户")))
+}
+
+func TestDocHandlerSearch(t *testing.T) {
+	service := newEnvironmentService()
+	service.AddApi(apidocbuilder.Api{Name: "addUser", Title: "新增用户", Method: "POST", Path: "/user/add"})
+	handler := apidocbuilder.NewDocHandler(*service, "/docs")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs?q=新增", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	require.Contains(t, body, `value="新增"`)
+	require.Contains(t, body, `href="/docs?name=addUser&amp;q=%E6%96%B0%E5%A2%9E"`)
+	require.Equal(t, 1, strings.Count(body, `<li data-search=`))
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 type Service struct {
@@ -73,13 +75,15 @@ func (s *Service) AddServer(servers ...Server) {
 		s.Servers = append(s.Servers, server)
 	}
 
-	// 去重
-	m := make(map[string]Server)
-	for _, server := range s.Servers {
-		m[server.Name] = server
-	}
+	// 按名称去重,保留首次出现的位置,后添加的覆盖先添加的
+	index := make(map[string]int)
 	newServers := make([]Server, 0)
-	for _, server := range m {
+	for _, server := range s.Servers {
+		if i, ok := index[server.Name]; ok {
+			newServers[i] = server
+			continue
+		}
+		index[server.Name] = len(newServers)
 		newServers = append(newServers, server)
 	}
 	s.Servers = newServers
@@ -160,9 +164,23 @@ func (s *Service) AddNavigate(navigates ...Navigate) {
 	s.Navigates = newNavigates
 }
 
+const (
+	SERVER_ENVIRONMENT_DEV     = "dev"
+	SERVER_ENVIRONMENT_TEST    = "test"
+	SERVER_ENVIRONMENT_STAGING = "staging"
+	SERVER_ENVIRONMENT_PROD    = "prod"
+)
+
+var ERROR_NOT_FOUND_SERVER = errors.New("not found server")
+
+// Server 服务器即环境,每个环境有独立的地址、代理和变量
 type Server struct {
 	// 服务器名称
 	Name string `json:"name"`
+	// 环境(dev/test/staging/prod),为空时使用 Name
+	Environment string `json:"environment,omitempty"`
+	// 默认环境,都未设置时第一个为默认环境
+	Default bool `json:"default,omitempty"`
 
 	Title string `json:"title"`
 	// url地址
@@ -175,6 +193,16 @@ type Server struct {
 	Proxy string `json:"proxy"`
 	// 扩展字段
 	ExtensionIds string `json:"extensionIds"`
+	// 服务器变量,优先级高于服务变量
+	Variables Variables `json:"variables,omitempty"`
+}
+
+// GetEnvironment 环境名称,未设置时使用服务器名称
+func (server Server) GetEnvironment() string {
+	if server.Environment != "" {
+		return server.Environment
+	}
+	return server.Name
 }
 
 type Servers []Server
@@ -194,6 +222,56 @@ func (s Servers) GetFirst() (server Server) {
 	}
 	return server
 }
+
+// GetDefault 获取默认环境,未设置默认时取第一个
+func (s Servers) GetDefault() (server Server) {
+	for _, server := range s {
+		if server.Default {
+			return server
+		}
+	}
+	return s.GetFirst()
+}
+
+// GetByEnvironment 按环境名称(其次服务器名称)获取,environment 为空时返回默认环境
+func (s Servers) GetByEnvironment(environment string) (server Server, err error) {
+	if environment == "" {
+		return s.GetDefault(), nil
+	}
+	for _, server := range s {
+		if strings.EqualFold(server.GetEnvironment(), environment) {
+			return server, nil
+		}
+	}
+	for _, server := range s {
+		if strings.EqualFold(server.Name, environment) {
+			return server, nil
+		}
+	}
+	err = errors.WithMessagef(ERROR_NOT_FOUND_SERVER, "environment:%s", environment)
+	return server, err
+}
+
+// Environments 按顺序返回所有环境名称
+func (s Servers) Environments() (environments []string) {
+	environments = make([]string, 0, len(s))
+	for _, server := range s {
+		environments = append(environments, server.GetEnvironment())
+	}
+	return environments
+}
+
+// SetDefault 设置默认环境
+func (s Servers) SetDefault(environment string) (err error) {
+	server, err := s.GetByEnvironment(environment)
+	if err != nil {
+		return err
+	}
+	for i := range s {
+		s[i].Default = s[i].Name == server.Name
+	}
+	return nil
+}
 func (s Servers) Json() (str string, err error) {
 	b, err := json.Marshal(s)
 	if err != nil {
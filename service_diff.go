@@ -0,0 +1,395 @@
+package apidocbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CHANGE_ACTION_ADDED   = "added"
+	CHANGE_ACTION_REMOVED = "removed"
+	CHANGE_ACTION_CHANGED = "changed"
+)
+
+// 变更位置,参数位置与 Api 的 json 字段一致
+const (
+	CHANGE_POSITION_API             = "api"
+	CHANGE_POSITION_REQUEST_HEADER  = "requestHeader"
+	CHANGE_POSITION_QUERY           = "query"
+	CHANGE_POSITION_REQUEST_BODY    = "requestBody"
+	CHANGE_POSITION_RESPONSE_HEADER = "responseHeader"
+	CHANGE_POSITION_RESPONSE_BODY   = "responseBody"
+)
+
+// 变更的属性
+const (
+	CHANGE_FIELD_METHOD                = "method"
+	CHANGE_FIELD_PATH                  = "path"
+	CHANGE_FIELD_REQUEST_CONTENT_TYPE  = "requestContentType"
+	CHANGE_FIELD_RESPONSE_CONTENT_TYPE = "responseContentType"
+	CHANGE_FIELD_TYPE                  = "type"
+	CHANGE_FIELD_FORMAT                = "format"
+	CHANGE_FIELD_REQUIRED              = "required"
+	CHANGE_FIELD_DEPRECATED            = "deprecated"
+	CHANGE_FIELD_ENUM                  = "enum"
+	CHANGE_FIELD_PATTERN               = "pattern"
+	CHANGE_FIELD_MIN_LENGTH            = "minLength"
+	CHANGE_FIELD_MAX_LENGTH            = "maxLength"
+	CHANGE_FIELD_MINIMUM               = "minimum"
+	CHANGE_FIELD_MAXIMUM               = "maximum"
+	CHANGE_FIELD_MIN_ITEMS             = "minItems"
+	CHANGE_FIELD_MAX_ITEMS             = "maxItems"
+)
+
+// ApiChange 两个版本之间的一处变更
+type ApiChange struct {
+	Api      string `json:"api"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Position string `json:"position"`
+	// 参数全称,接口级变更为空
+	Fullname string `json:"fullname,omitempty"`
+	// 变化的属性,新增、删除时为空
+	Field  string `json:"field,omitempty"`
+	Action string `json:"action"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+	// 是否不兼容(已有调用方需要修改)
+	Breaking bool   `json:"breaking"`
+	Message  string `json:"message"`
+}
+
+type ApiChanges []ApiChange
+
+func (cs ApiChanges) Breaking() (breaking ApiChanges) {
+	breaking = make(ApiChanges, 0)
+	for _, c := range cs {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+func (cs ApiChanges) NonBreaking() (nonBreaking ApiChanges) {
+	nonBreaking = make(ApiChanges, 0)
+	for _, c := range cs {
+		if !c.Breaking {
+			nonBreaking = append(nonBreaking, c)
+		}
+	}
+	return nonBreaking
+}
+
+// ServiceDiff 两个版本服务的接口变更
+type ServiceDiff struct {
+	Service    string     `json:"service"`
+	OldVersion string     `json:"oldVersion"`
+	NewVersion string     `json:"newVersion"`
+	Changes    ApiChanges `json:"changes"`
+}
+
+func (d ServiceDiff) Breaking() ApiChanges    { return d.Changes.Breaking() }
+func (d ServiceDiff) NonBreaking() ApiChanges { return d.Changes.NonBreaking() }
+func (d ServiceDiff) HasBreaking() bool       { return len(d.Breaking()) > 0 }
+
+// Markdown 生成 markdown 报告,不兼容变更在前
+func (d ServiceDiff) Markdown() (out []byte, err error) {
+	return ExecTpl(TPL_NAME_MARKDOWN_DIFF, d)
+}
+
+func (d ServiceDiff) Json() (out []byte, err error) {
+	out, err = json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		err = errors.WithMessage(err, "marshal service diff")
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiffService 比较两个版本的服务,接口先按 method+path 匹配,未匹配的再按名称匹配(路径、方法变更)
+func DiffService(old Service, new Service) (diff ServiceDiff) {
+	diff = ServiceDiff{Service: firstNotEmpty(new.Name, old.Name), OldVersion: old.Version, NewVersion: new.Version, Changes: make(ApiChanges, 0)}
+	matched := make(map[int]bool)
+	pairs := make([][2]*Api, 0)
+	removed := make(Apis, 0)
+	for i := range old.Apis {
+		oldApi := &old.Apis[i]
+		j := findApi(new.Apis, matched, func(api Api) bool { return api.IsSameMethodAndPath(oldApi.Method, oldApi.Path) })
+		if j < 0 && oldApi.Name != "" {
+			j = findApi(new.Apis, matched, func(api Api) bool { return api.IsSameName(oldApi.Name) })
+		}
+		if j < 0 {
+			removed = append(removed, *oldApi)
+			continue
+		}
+		matched[j] = true
+		pairs = append(pairs, [2]*Api{oldApi, &new.Apis[j]})
+	}
+	for _, api := range removed {
+		diff.Changes = append(diff.Changes, newApiChange(api, CHANGE_POSITION_API, "", "", CHANGE_ACTION_REMOVED, true, "接口已删除"))
+	}
+	for _, pair := range pairs {
+		diff.Changes = append(diff.Changes, DiffApi(*pair[0], *pair[1])...)
+	}
+	for j, api := range new.Apis {
+		if !matched[j] {
+			diff.Changes = append(diff.Changes, newApiChange(api, CHANGE_POSITION_API, "", "", CHANGE_ACTION_ADDED, false, "新增接口"))
+		}
+	}
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Breaking && !diff.Changes[j].Breaking
+	})
+	return diff
+}
+
+func findApi(apis Apis, matched map[int]bool, fn func(api Api) bool) int {
+	for j, api := range apis {
+		if !matched[j] && fn(api) {
+			return j
+		}
+	}
+	return -1
+}
+
+// DiffApi 比较同一接口的两个版本,请求参数收紧、响应参数放宽为不兼容变更
+func DiffApi(old Api, new Api) (changes ApiChanges) {
+	changes = make(ApiChanges, 0)
+	change := func(field string, oldValue string, newValue string, breaking bool, message string) {
+		if strings.EqualFold(oldValue, newValue) {
+			return
+		}
+		c := newApiChange(new, CHANGE_POSITION_API, "", field, CHANGE_ACTION_CHANGED, breaking, message)
+		c.Old, c.New = oldValue, newValue
+		changes = append(changes, c)
+	}
+	change(CHANGE_FIELD_METHOD, old.Method, new.Method, true, "请求方法变更")
+	change(CHANGE_FIELD_PATH, old.Path, new.Path, true, "路径变更")
+	change(CHANGE_FIELD_REQUEST_CONTENT_TYPE, old.RequestContentType, new.RequestContentType, true, "请求格式变更")
+	change(CHANGE_FIELD_RESPONSE_CONTENT_TYPE, old.ResponseContentType, new.ResponseContentType, true, "响应格式变更")
+
+	positions := []struct {
+		position string
+		old, new Parameters
+	}{
+		{CHANGE_POSITION_REQUEST_HEADER, Parameters(old.RequestHeader), Parameters(new.RequestHeader)},
+		{CHANGE_POSITION_QUERY, Parameters(old.Query), Parameters(new.Query)},
+		{CHANGE_POSITION_REQUEST_BODY, old.RequestBody, new.RequestBody},
+		{CHANGE_POSITION_RESPONSE_HEADER, Parameters(old.ResponseHeader), Parameters(new.ResponseHeader)},
+		{CHANGE_POSITION_RESPONSE_BODY, old.ResponseBody, new.ResponseBody},
+	}
+	for _, p := range positions {
+		changes = append(changes, diffParameters(new, p.position, p.old, p.new)...)
+	}
+	return changes
+}
+
+func isRequestPosition(position string) bool {
+	return position == CHANGE_POSITION_REQUEST_HEADER || position == CHANGE_POSITION_QUERY || position == CHANGE_POSITION_REQUEST_BODY
+}
+
+func diffParameters(api Api, position string, olds Parameters, news Parameters) (changes ApiChanges) {
+	request := isRequestPosition(position)
+	oldMap := make(map[string]Parameter)
+	for _, p := range olds {
+		oldMap[parameterDiffKey(p)] = p
+	}
+	newKeys := make(map[string]bool)
+	for _, p := range news {
+		key := parameterDiffKey(p)
+		newKeys[key] = true
+		oldParameter, ok := oldMap[key]
+		if !ok {
+			required := parameterRequired(p)
+			message := "新增可选参数"
+			if required {
+				message = "新增必填参数"
+			}
+			if !request {
+				message = "新增返回字段"
+			}
+			changes = append(changes, newApiChange(api, position, key, "", CHANGE_ACTION_ADDED, request && required, message))
+			continue
+		}
+		changes = append(changes, diffParameter(api, position, key, oldParameter, p)...)
+	}
+	for _, p := range olds {
+		key := parameterDiffKey(p)
+		if newKeys[key] {
+			continue
+		}
+		message := "删除请求参数,调用方传入时将被忽略"
+		if !request {
+			message = "删除返回字段"
+		}
+		changes = append(changes, newApiChange(api, position, key, "", CHANGE_ACTION_REMOVED, !request, message))
+	}
+	return changes
+}
+
+func parameterDiffKey(p Parameter) string {
+	return firstNotEmpty(p.Fullname, p.Name)
+}
+
+func parameterRequired(p Parameter) bool {
+	return p.Required || p.Schema.Required
+}
+
+// diffParameter tightened 表示取值范围收紧,请求参数收紧、响应字段放宽时不兼容
+func diffParameter(api Api, position string, fullname string, old Parameter, new Parameter) (changes ApiChanges) {
+	request := isRequestPosition(position)
+	changes = make(ApiChanges, 0)
+	add := func(field string, oldValue string, newValue string, breaking bool, message string) {
+		c := newApiChange(api, position, fullname, field, CHANGE_ACTION_CHANGED, breaking, message)
+		c.Old, c.New = oldValue, newValue
+		changes = append(changes, c)
+	}
+	constraint := func(field string, oldValue string, newValue string, tightened bool) {
+		if oldValue == newValue {
+			return
+		}
+		message := fmt.Sprintf("%s 由 %s 改为 %s", field, emptyAs(oldValue, "无"), emptyAs(newValue, "无"))
+		add(field, oldValue, newValue, tightened == request, message)
+	}
+
+	oldSchema, newSchema := parameter2OpenAPISchema(old), parameter2OpenAPISchema(new)
+	if oldType, newType := oldSchema.Type.Main(), newSchema.Type.Main(); oldType != newType {
+		add(CHANGE_FIELD_TYPE, oldType, newType, true, fmt.Sprintf("类型由 %s 改为 %s", emptyAs(oldType, "无"), emptyAs(newType, "无")))
+	}
+	if oldSchema.Format != newSchema.Format {
+		add(CHANGE_FIELD_FORMAT, oldSchema.Format, newSchema.Format, true, fmt.Sprintf("格式由 %s 改为 %s", emptyAs(oldSchema.Format, "无"), emptyAs(newSchema.Format, "无")))
+	}
+	if oldRequired, newRequired := parameterRequired(old), parameterRequired(new); oldRequired != newRequired {
+		message := "改为非必填"
+		if newRequired {
+			message = "改为必填"
+		}
+		// 请求参数改为必填、响应字段改为非必返回时不兼容
+		add(CHANGE_FIELD_REQUIRED, strconv.FormatBool(oldRequired), strconv.FormatBool(newRequired), newRequired == request, message)
+	}
+	if oldSchema.Deprecated != newSchema.Deprecated {
+		add(CHANGE_FIELD_DEPRECATED, strconv.FormatBool(oldSchema.Deprecated), strconv.FormatBool(newSchema.Deprecated), false, "弃用标记变更")
+	}
+	changes = append(changes, diffEnum(api, position, fullname, old, new)...)
+	constraint(CHANGE_FIELD_PATTERN, oldSchema.Pattern, newSchema.Pattern, newSchema.Pattern != "")
+	constraint(CHANGE_FIELD_MIN_LENGTH, intPointerString(oldSchema.MinLength), intPointerString(newSchema.MinLength), lowerBoundTightened(intPointerFloat(oldSchema.MinLength), intPointerFloat(newSchema.MinLength)))
+	constraint(CHANGE_FIELD_MAX_LENGTH, intPointerString(oldSchema.MaxLength), intPointerString(newSchema.MaxLength), upperBoundTightened(intPointerFloat(oldSchema.MaxLength), intPointerFloat(newSchema.MaxLength)))
+	constraint(CHANGE_FIELD_MINIMUM, floatPointerString(oldSchema.Minimum), floatPointerString(newSchema.Minimum), lowerBoundTightened(oldSchema.Minimum, newSchema.Minimum))
+	constraint(CHANGE_FIELD_MAXIMUM, floatPointerString(oldSchema.Maximum), floatPointerString(newSchema.Maximum), upperBoundTightened(oldSchema.Maximum, newSchema.Maximum))
+	constraint(CHANGE_FIELD_MIN_ITEMS, intPointerString(oldSchema.MinItems), intPointerString(newSchema.MinItems), lowerBoundTightened(intPointerFloat(oldSchema.MinItems), intPointerFloat(newSchema.MinItems)))
+	constraint(CHANGE_FIELD_MAX_ITEMS, intPointerString(oldSchema.MaxItems), intPointerString(newSchema.MaxItems), upperBoundTightened(intPointerFloat(oldSchema.MaxItems), intPointerFloat(newSchema.MaxItems)))
+	return changes
+}
+
+// diffEnum 请求参数删除枚举值、响应字段新增枚举值时不兼容
+func diffEnum(api Api, position string, fullname string, old Parameter, new Parameter) (changes ApiChanges) {
+	request := isRequestPosition(position)
+	oldEnum, newEnum := parameterEnum(old), parameterEnum(new)
+	if strings.Join(oldEnum, ",") == strings.Join(newEnum, ",") {
+		return nil
+	}
+	added, removed := make([]string, 0), make([]string, 0)
+	for _, v := range newEnum {
+		if !containsString(oldEnum, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range oldEnum {
+		if !containsString(newEnum, v) {
+			removed = append(removed, v)
+		}
+	}
+	var (
+		breaking bool
+		messages []string
+	)
+	switch {
+	case len(oldEnum) == 0: // 由任意值改为枚举
+		breaking = request
+		messages = append(messages, fmt.Sprintf("限制取值为 %s", strings.Join(newEnum, ",")))
+	case len(newEnum) == 0:
+		breaking = !request
+		messages = append(messages, "取消枚举限制")
+	default:
+		if len(added) > 0 {
+			breaking = breaking || !request
+			messages = append(messages, fmt.Sprintf("新增枚举值 %s", strings.Join(added, ",")))
+		}
+		if len(removed) > 0 {
+			breaking = breaking || request
+			messages = append(messages, fmt.Sprintf("删除枚举值 %s", strings.Join(removed, ",")))
+		}
+		if len(messages) == 0 {
+			messages = append(messages, "枚举值顺序变更")
+		}
+	}
+	c := newApiChange(api, position, fullname, CHANGE_FIELD_ENUM, CHANGE_ACTION_CHANGED, breaking, strings.Join(messages, ";"))
+	c.Old, c.New = strings.Join(oldEnum, ","), strings.Join(newEnum, ",")
+	return ApiChanges{c}
+}
+
+func parameterEnum(p Parameter) (enum []string) {
+	s := firstNotEmpty(p.Enum, p.Schema.Enum)
+	if s == "" {
+		return nil
+	}
+	return splitEnum(s)
+}
+
+func newApiChange(api Api, position string, fullname string, field string, action string, breaking bool, message string) ApiChange {
+	return ApiChange{
+		Api:      api.Name,
+		Method:   strings.ToUpper(api.Method),
+		Path:     api.Path,
+		Position: position,
+		Fullname: fullname,
+		Field:    field,
+		Action:   action,
+		Breaking: breaking,
+		Message:  message,
+	}
+}
+
+// lowerBoundTightened 下限新增或变大
+func lowerBoundTightened(old *float64, new *float64) bool {
+	return new != nil && (old == nil || *new > *old)
+}
+
+// upperBoundTightened 上限新增或变小
+func upperBoundTightened(old *float64, new *float64) bool {
+	return new != nil && (old == nil || *new < *old)
+}
+
+func intPointerFloat(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+func intPointerString(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+func floatPointerString(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func emptyAs(s string, placeholder string) string {
+	if s == "" {
+		return placeholder
+	}
+	return s
+}
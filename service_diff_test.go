@@ -0,0 +1,88 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestDiffService(t *testing.T) {
+	minLength := 1
+	old := apidocbuilder.Service{Name: "user", Version: "v1", Apis: apidocbuilder.Apis{
+		{Name: "addUser", Method: "POST", Path: "/user/add",
+			RequestBody: apidocbuilder.Parameters{
+				{Fullname: "name", Type: "string", Required: true},
+				{Fullname: "status", Type: "int", Enum: "1,2"},
+				{Fullname: "remark", Type: "string"},
+			},
+			ResponseBody: apidocbuilder.Parameters{{Fullname: "id", Type: "int", Required: true}, {Fullname: "level", Type: "int", Enum: "1,2"}},
+		},
+		{Name: "getUser", Method: "GET", Path: "/user/get", Query: apidocbuilder.Query{{Fullname: "id", Type: "string", Schema: apidocbuilder.Schema{MaxLength: 32}}}},
+		{Name: "delUser", Method: "POST", Path: "/user/del"},
+	}}
+	new := apidocbuilder.Service{Name: "user", Version: "v2", Apis: apidocbuilder.Apis{
+		{Name: "addUser", Method: "POST", Path: "/user/add",
+			RequestBody: apidocbuilder.Parameters{
+				{Fullname: "name", Type: "string", Required: true, Schema: apidocbuilder.Schema{MinLength: minLength}},
+				{Fullname: "status", Type: "int", Enum: "1,2,3"},
+				{Fullname: "mobile", Type: "string", Required: true},
+				{Fullname: "email", Type: "string"},
+			},
+			ResponseBody: apidocbuilder.Parameters{{Fullname: "id", Type: "string", Required: true}, {Fullname: "level", Type: "int", Enum: "1,2,3"}},
+		},
+		{Name: "getUser", Method: "GET", Path: "/v2/user/get", Query: apidocbuilder.Query{{Fullname: "id", Type: "string", Schema: apidocbuilder.Schema{MaxLength: 64}}}},
+		{Name: "listUser", Method: "GET", Path: "/user/list"},
+	}}
+
+	diff := apidocbuilder.DiffService(old, new)
+	find := func(api string, fullname string, field string) apidocbuilder.ApiChange {
+		for _, c := range diff.Changes {
+			if c.Api == api && c.Fullname == fullname && c.Field == field {
+				return c
+			}
+		}
+		require.Failf(t, "change not found", "%s %s %s", api, fullname, field)
+		return apidocbuilder.ApiChange{}
+	}
+
+	require.True(t, diff.HasBreaking())
+	require.True(t, find("delUser", "", "").Breaking)
+	require.Equal(t, apidocbuilder.CHANGE_ACTION_REMOVED, find("delUser", "", "").Action)
+	require.False(t, find("listUser", "", "").Breaking)
+	// 按名称匹配到路径变更的接口
+	pathChange := find("getUser", "", apidocbuilder.CHANGE_FIELD_PATH)
+	require.True(t, pathChange.Breaking)
+	require.Equal(t, "/v2/user/get", pathChange.New)
+	require.False(t, find("getUser", "id", apidocbuilder.CHANGE_FIELD_MAX_LENGTH).Breaking)
+
+	require.True(t, find("addUser", "mobile", "").Breaking)
+	require.False(t, find("addUser", "email", "").Breaking)
+	require.False(t, find("addUser", "remark", "").Breaking)
+	require.True(t, find("addUser", "name", apidocbuilder.CHANGE_FIELD_MIN_LENGTH).Breaking)
+	require.False(t, find("addUser", "status", apidocbuilder.CHANGE_FIELD_ENUM).Breaking)
+	require.True(t, find("addUser", "level", apidocbuilder.CHANGE_FIELD_ENUM).Breaking)
+	typeChange := find("addUser", "id", apidocbuilder.CHANGE_FIELD_TYPE)
+	require.True(t, typeChange.Breaking)
+	require.Equal(t, apidocbuilder.CHANGE_POSITION_RESPONSE_BODY, typeChange.Position)
+
+	// 不兼容变更排在前面
+	for i := 1; i < len(diff.Changes); i++ {
+		require.False(t, !diff.Changes[i-1].Breaking && diff.Changes[i].Breaking)
+	}
+
+	md, err := diff.Markdown()
+	require.NoError(t, err)
+	require.Contains(t, string(md), "# user 接口变更 v1 -> v2")
+	require.Contains(t, string(md), "|POST /user/add|requestBody|mobile|added|新增必填参数|")
+
+	b, err := diff.Json()
+	require.NoError(t, err)
+	var decoded apidocbuilder.ServiceDiff
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, diff, decoded)
+
+	require.False(t, apidocbuilder.DiffService(old, old).HasBreaking())
+	require.Empty(t, apidocbuilder.DiffService(old, old).Changes)
+}
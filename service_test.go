@@ -0,0 +1,76 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func newEnvironmentService() *apidocbuilder.Service {
+	service := &apidocbuilder.Service{Name: "user"}
+	service.AddServer(
+		apidocbuilder.Server{Name: "dev", Environment: apidocbuilder.SERVER_ENVIRONMENT_DEV, URL: "http://dev.example.com", Variables: apidocbuilder.Variables{{Name: "token", Value: "dev-token"}}},
+		apidocbuilder.Server{Name: "test", Environment: apidocbuilder.SERVER_ENVIRONMENT_TEST, URL: "http://test.example.com"},
+		apidocbuilder.Server{Name: "prod", Environment: apidocbuilder.SERVER_ENVIRONMENT_PROD, URL: "https://{{domain}}", Proxy: "http://127.0.0.1:8888", Default: true, Variables: apidocbuilder.Variables{{Name: "domain", Value: "api.example.com"}, {Name: "token", Value: "prod-token"}}},
+	)
+	service.AddApi(apidocbuilder.Api{
+		Name: "getUser", Method: "GET", Path: "/user",
+		RequestHeader: apidocbuilder.Header{{Fullname: "Authorization", Default: "{{token}}"}},
+	})
+	return service
+}
+
+func TestServersEnvironment(t *testing.T) {
+	service := newEnvironmentService()
+	service.AddServer(apidocbuilder.Server{Name: "test", Environment: apidocbuilder.SERVER_ENVIRONMENT_TEST, URL: "http://test2.example.com"})
+	require.Equal(t, []string{"dev", "test", "prod"}, service.Servers.Environments())
+	require.Equal(t, "http://test2.example.com", service.Servers[1].URL)
+	require.Equal(t, "prod", service.Servers.GetDefault().Name)
+
+	server, err := service.Servers.GetByEnvironment("TEST")
+	require.NoError(t, err)
+	require.Equal(t, "test", server.Name)
+	_, err = service.Servers.GetByEnvironment("staging")
+	require.True(t, errors.Is(err, apidocbuilder.ERROR_NOT_FOUND_SERVER))
+
+	require.NoError(t, service.Servers.SetDefault("dev"))
+	require.Equal(t, "dev", service.Servers.GetDefault().Name)
+	require.False(t, service.Servers[2].Default)
+}
+
+func TestApiEnvironment(t *testing.T) {
+	service := newEnvironmentService()
+	api := service.Apis[0]
+
+	curl, err := api.CURLExample()
+	require.NoError(t, err)
+	require.Contains(t, curl, "https://api.example.com/user")
+	require.Contains(t, curl, "prod-token")
+
+	curl, err = api.CURLExampleWithEnvironment("dev")
+	require.NoError(t, err)
+	require.Contains(t, curl, "http://dev.example.com/user")
+	require.Contains(t, curl, "dev-token")
+
+	example, err := api.ExampleWithEnvironment("prod")
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:8888", example.Proxy)
+	request, err := api.SnippetRequest(service.Servers.GetDefault(), example)
+	require.NoError(t, err)
+	require.Equal(t, "https://api.example.com/user", request.URL)
+
+	_, err = api.CURLExampleWithEnvironment("staging")
+	require.Error(t, err)
+
+	form, err := apidocbuilder.NewHtmxFormWithEnvironment(api, "test")
+	require.NoError(t, err)
+	require.Equal(t, "http://test.example.com/user", form.Action)
+	require.Equal(t, "test", form.Environment)
+
+	out, err := apidocbuilder.RenderForm(apidocbuilder.ServiceRender{Service: *service, Environment: "dev"}, "getUser")
+	require.NoError(t, err)
+	require.Contains(t, string(out), `<option value="dev" selected>`)
+	require.Contains(t, string(out), `hx-post="/proxy?env=dev&amp;name=getUser"`)
+}
@@ -0,0 +1,541 @@
+package apidocbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ERROR_NOT_FOUND_SNIPPET_GENERATOR = errors.New("not found snippet generator")
+
+// Snippet 可直接运行的请求代码
+type Snippet struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Code     string `json:"code"`
+}
+
+type Snippets []Snippet
+
+// SnippetPair 有序的键值对(请求头、表单字段),保证生成的代码稳定
+type SnippetPair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// SnippetRequest 生成代码所需的请求信息,由 Api+Server+Example 计算得出,生成器只负责拼装代码
+type SnippetRequest struct {
+	Method      string        `json:"method"`
+	URL         string        `json:"url"`
+	Proxy       string        `json:"proxy"`
+	Headers     []SnippetPair `json:"headers"`
+	ContentType string        `json:"contentType"`
+	Body        string        `json:"body"`
+	// multipart/form-data 字段,有值时忽略 Body
+	FormData []SnippetPair `json:"formData"`
+}
+
+func (r SnippetRequest) IsJson() bool {
+	return strings.Contains(strings.ToLower(r.ContentType), "json")
+}
+
+// Resolve 替换地址、请求头、请求体中的变量
+func (r SnippetRequest) Resolve(resolver *VariableResolver) (resolved SnippetRequest) {
+	if resolver == nil {
+		return r
+	}
+	resolved = r
+	resolved.URL, resolved.Proxy = resolver.Resolve(r.URL), resolver.Resolve(r.Proxy)
+	resolved.ContentType, resolved.Body = resolver.Resolve(r.ContentType), resolver.Resolve(r.Body)
+	resolved.Headers, resolved.FormData = resolveSnippetPairs(r.Headers, resolver), resolveSnippetPairs(r.FormData, resolver)
+	return resolved
+}
+
+func resolveSnippetPairs(pairs []SnippetPair, resolver *VariableResolver) (resolved []SnippetPair) {
+	if pairs == nil {
+		return nil
+	}
+	resolved = make([]SnippetPair, 0, len(pairs))
+	for _, pair := range pairs {
+		resolved = append(resolved, SnippetPair{Key: pair.Key, Value: resolver.Resolve(pair.Value)})
+	}
+	return resolved
+}
+
+type SnippetGenerator struct {
+	// 语言,取 LanguageAlias 标准名称
+	Language string
+	Title    string
+	Generate func(request SnippetRequest) (code string, err error)
+}
+
+// SnippetGenerators 有序,顺序即文档中标签页顺序
+type SnippetGenerators []SnippetGenerator
+
+// Get 按语言获取生成器,支持语言别名
+func (gs SnippetGenerators) Get(language string) (generator SnippetGenerator, err error) {
+	alias := LanguageAliasDefault.GetByLanguage(language)
+	for _, g := range gs {
+		for _, alia := range alias {
+			if strings.EqualFold(g.Language, alia) {
+				return g, nil
+			}
+		}
+	}
+	err = errors.WithMessagef(ERROR_NOT_FOUND_SNIPPET_GENERATOR, "language:%s", language)
+	return generator, err
+}
+
+// Register 注册生成器,同语言的覆盖
+func (gs *SnippetGenerators) Register(generators ...SnippetGenerator) {
+	for _, generator := range generators {
+		replaced := false
+		for i, g := range *gs {
+			if strings.EqualFold(g.Language, generator.Language) {
+				(*gs)[i], replaced = generator, true
+				break
+			}
+		}
+		if !replaced {
+			*gs = append(*gs, generator)
+		}
+	}
+}
+
+var SnippetGeneratorsDefault = SnippetGenerators{
+	{Language: LANGUAGE_BASH, Title: "cURL", Generate: curlSnippet},
+	{Language: LANGUAGE_HTTPIE, Title: "HTTPie", Generate: httpieSnippet},
+	{Language: LANGUAGE_JAVASCRIPT, Title: "JavaScript fetch", Generate: fetchSnippet},
+	{Language: LANGUAGE_GO, Title: "Go net/http", Generate: goSnippet},
+	{Language: LANGUAGE_PYTHON, Title: "Python requests", Generate: pythonSnippet},
+	{Language: LANGUAGE_PHP, Title: "PHP cURL", Generate: phpSnippet},
+}
+
+// Snippet 生成指定语言的请求代码
+func (api Api) Snippet(language string, server Server, example Example) (snippet Snippet, err error) {
+	generator, err := SnippetGeneratorsDefault.Get(language)
+	if err != nil {
+		return snippet, err
+	}
+	request, err := api.SnippetRequest(server, example)
+	if err != nil {
+		return snippet, err
+	}
+	return generator.snippet(request)
+}
+
+// Snippets 按 SnippetGeneratorsDefault 顺序生成所有语言的请求代码
+func (api Api) Snippets(server Server, example Example) (snippets Snippets, err error) {
+	request, err := api.SnippetRequest(server, example)
+	if err != nil {
+		return nil, err
+	}
+	return snippetsFromRequest(request)
+}
+
+func snippetsFromRequest(request SnippetRequest) (snippets Snippets, err error) {
+	snippets = make(Snippets, 0, len(SnippetGeneratorsDefault))
+	for _, generator := range SnippetGeneratorsDefault {
+		snippet, err := generator.snippet(request)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, snippet)
+	}
+	return snippets, nil
+}
+
+// GetSnippets 使用默认环境、第一个案例生成请求代码 模板中有使用
+func (api Api) GetSnippets() (snippets Snippets, err error) {
+	server, _ := api.GetServer("")
+	example := *api.GetFirstExample()
+	resolver := api.VariableResolver(server, example)
+	resolver.Builtins = nil // 动态变量保留占位符,同一文档每次生成的内容一致
+	request, err := api.SnippetRequestWithResolver(server, example, resolver)
+	if err != nil {
+		return nil, err
+	}
+	return snippetsFromRequest(request)
+}
+
+func (g SnippetGenerator) snippet(request SnippetRequest) (snippet Snippet, err error) {
+	code, err := g.Generate(request)
+	if err != nil {
+		err = errors.WithMessagef(err, "generate %s snippet", g.Language)
+		return snippet, err
+	}
+	snippet = Snippet{Language: g.Language, Title: g.Title, Code: code}
+	return snippet, nil
+}
+
+// SnippetRequest 计算请求信息:案例有值优先使用案例,否则由接口参数生成,{{name}} 变量按 api.VariableResolver 替换
+func (api Api) SnippetRequest(server Server, example Example) (request SnippetRequest, err error) {
+	return api.SnippetRequestWithResolver(server, example, api.VariableResolver(server, example))
+}
+
+// SnippetRequestWithResolver 使用指定的变量解析器,resolver 为空时不替换变量
+func (api Api) SnippetRequestWithResolver(server Server, example Example, resolver *VariableResolver) (request SnippetRequest, err error) {
+	server.URL, server.Proxy = resolver.Resolve(server.URL), resolver.Resolve(server.Proxy)
+	example.URL, example.Proxy = resolver.Resolve(example.URL), resolver.Resolve(example.Proxy)
+	defer func() {
+		if err == nil {
+			request = request.Resolve(resolver)
+		}
+	}()
+	request = SnippetRequest{
+		Method:      strings.ToUpper(example.Method),
+		Proxy:       example.Proxy,
+		ContentType: example.ContentType,
+		Body:        example.RequestBody,
+	}
+	if request.Method == "" {
+		request.Method = strings.ToUpper(api.Method)
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	if request.Proxy == "" {
+		request.Proxy = server.Proxy
+	}
+	request.URL, err = api.snippetURL(server.URL, example.URL)
+	if err != nil {
+		return request, err
+	}
+
+	headers := example.Headers
+	if len(headers) == 0 {
+		headers = api.RequestHeader.ToMap()
+	}
+	for _, key := range sortedKeys(headers) {
+		if strings.EqualFold(key, HEADER_NAME_CONTENT_TYPE) {
+			if request.ContentType == "" {
+				request.ContentType = headers[key]
+			}
+			continue
+		}
+		request.Headers = append(request.Headers, SnippetPair{Key: key, Value: headers[key]})
+	}
+	if request.ContentType == "" {
+		request.ContentType = api.RequestContentType
+	}
+
+	hasBody := request.Method != "GET" && request.Method != "HEAD"
+	if hasBody && request.Body == "" && len(api.RequestBody) > 0 {
+		request.Body, err = api.snippetBody(request.ContentType)
+		if err != nil {
+			return request, err
+		}
+	}
+	if request.Body == "" {
+		return request, nil
+	}
+	if request.ContentType == "" {
+		request.ContentType = Header_Value_Content_Type_Json
+	}
+	if strings.Contains(strings.ToLower(request.ContentType), "multipart/form-data") {
+		values, err := url.ParseQuery(request.Body)
+		if err != nil {
+			err = errors.WithMessage(err, "parse multipart body")
+			return request, err
+		}
+		for _, key := range sortedKeys(values) {
+			for _, value := range values[key] {
+				request.FormData = append(request.FormData, SnippetPair{Key: key, Value: value})
+			}
+		}
+		request.Body = ""
+		return request, nil // multipart 的 Content-Type 需要携带 boundary,由各语言的客户端生成
+	}
+	request.Headers = append([]SnippetPair{{Key: HEADER_NAME_CONTENT_TYPE, Value: request.ContentType}}, request.Headers...)
+	return request, nil
+}
+
+// snippetURL 案例地址为绝对地址时替换为所选服务器,为相对地址时拼接服务器地址,无案例时由路径和query参数生成
+func (api Api) snippetURL(serverURL string, exampleURL string) (fullURL string, err error) {
+	serverURL = strings.TrimRight(serverURL, "/")
+	if exampleURL != "" {
+		u, err := url.Parse(exampleURL)
+		if err != nil {
+			return "", err
+		}
+		if u.Host == "" {
+			return fmt.Sprintf("%s/%s", serverURL, strings.TrimLeft(exampleURL, "/")), nil
+		}
+		if serverURL == "" {
+			return exampleURL, nil
+		}
+		su, err := url.Parse(serverURL)
+		if err != nil {
+			return "", err
+		}
+		u.Scheme, u.Host = su.Scheme, su.Host
+		return u.String(), nil
+	}
+
+	path := api.Path
+	query := make(Query, 0)
+	for _, p := range api.Query {
+		if p.Position != OpenAPI_In_Path {
+			query = append(query, p)
+			continue
+		}
+		value := url.PathEscape(p.Value())
+		if value == "" {
+			continue
+		}
+		path = strings.ReplaceAll(path, fmt.Sprintf("{%s}", p.Fullname), value)
+		path = strings.ReplaceAll(path, fmt.Sprintf(":%s", p.Fullname), value)
+	}
+	fullURL = fmt.Sprintf("%s/%s", serverURL, strings.TrimLeft(path, "/"))
+	if rawQuery := query.Encode(); rawQuery != "" {
+		fullURL = fmt.Sprintf("%s?%s", fullURL, rawQuery)
+	}
+	return fullURL, nil
+}
+
+func (api Api) snippetBody(contentType string) (body string, err error) {
+	contentType = strings.ToLower(contentType)
+	if strings.Contains(contentType, "x-www-form-urlencoded") || strings.Contains(contentType, "multipart/form-data") {
+		ps := api.RequestBody
+		ps.FormatField()
+		values := url.Values{}
+		for _, p := range ps {
+			values.Add(p.Name, p.Value())
+		}
+		return values.Encode(), nil
+	}
+	lineschema := api.RequestBody.Lineschema("", false)
+	body, err = lineschema.JsonExample()
+	if err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+func curlSnippet(request SnippetRequest) (code string, err error) {
+	lines := []string{fmt.Sprintf("curl -X %s %s", request.Method, shellQuote(request.URL))}
+	if request.Proxy != "" {
+		lines = append(lines, fmt.Sprintf("-x %s", shellQuote(request.Proxy)))
+	}
+	for _, h := range request.Headers {
+		lines = append(lines, fmt.Sprintf("-H %s", shellQuote(fmt.Sprintf("%s: %s", h.Key, h.Value))))
+	}
+	for _, f := range request.FormData {
+		lines = append(lines, fmt.Sprintf("-F %s", shellQuote(fmt.Sprintf("%s=%s", f.Key, f.Value))))
+	}
+	if request.Body != "" {
+		lines = append(lines, fmt.Sprintf("--data-raw %s", shellQuote(request.Body)))
+	}
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+func httpieSnippet(request SnippetRequest) (code string, err error) {
+	command := []string{"http"}
+	if request.Proxy != "" {
+		u, err := url.Parse(request.URL)
+		if err != nil {
+			return "", err
+		}
+		command = append(command, fmt.Sprintf("--proxy=%s", shellQuote(fmt.Sprintf("%s:%s", u.Scheme, request.Proxy))))
+	}
+	if len(request.FormData) > 0 {
+		command = append(command, "--multipart")
+	}
+	if request.Body != "" {
+		command = append(command, fmt.Sprintf("--raw=%s", shellQuote(request.Body)))
+	}
+	command = append(command, request.Method, shellQuote(request.URL))
+	lines := []string{strings.Join(command, " ")}
+	for _, h := range request.Headers {
+		lines = append(lines, shellQuote(fmt.Sprintf("%s:%s", h.Key, h.Value)))
+	}
+	for _, f := range request.FormData {
+		lines = append(lines, shellQuote(fmt.Sprintf("%s=%s", f.Key, f.Value)))
+	}
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+func fetchSnippet(request SnippetRequest) (code string, err error) {
+	var w bytes.Buffer
+	if len(request.FormData) > 0 {
+		w.WriteString("const formData = new FormData();\n")
+		for _, f := range request.FormData {
+			w.WriteString(fmt.Sprintf("formData.append(%s, %s);\n", quoteString(f.Key), quoteString(f.Value)))
+		}
+		w.WriteString("\n")
+	}
+	if request.Proxy != "" {
+		w.WriteString(fmt.Sprintf("// fetch 不支持设置代理,请在运行环境中配置代理:%s\n", request.Proxy))
+	}
+	w.WriteString(fmt.Sprintf("const response = await fetch(%s, {\n", quoteString(request.URL)))
+	w.WriteString(fmt.Sprintf("  method: %s,\n", quoteString(request.Method)))
+	if len(request.Headers) > 0 {
+		w.WriteString("  headers: {\n")
+		for _, h := range request.Headers {
+			w.WriteString(fmt.Sprintf("    %s: %s,\n", quoteString(h.Key), quoteString(h.Value)))
+		}
+		w.WriteString("  },\n")
+	}
+	switch {
+	case len(request.FormData) > 0:
+		w.WriteString("  body: formData,\n")
+	case request.Body != "" && request.IsJson() && json.Valid([]byte(request.Body)):
+		w.WriteString(fmt.Sprintf("  body: JSON.stringify(%s),\n", request.Body))
+	case request.Body != "":
+		w.WriteString(fmt.Sprintf("  body: %s,\n", quoteString(request.Body)))
+	}
+	w.WriteString("});\n")
+	w.WriteString("console.log(response.status, await response.text());")
+	return w.String(), nil
+}
+
+func goSnippet(request SnippetRequest) (code string, err error) {
+	imports := []string{"fmt", "io", "net/http"}
+	if request.Body != "" {
+		imports = append(imports, "strings")
+	}
+	if len(request.FormData) > 0 {
+		imports = append(imports, "bytes", "mime/multipart")
+	}
+	if request.Proxy != "" {
+		imports = append(imports, "net/url")
+	}
+	sort.Strings(imports)
+
+	var w bytes.Buffer
+	w.WriteString("package main\n\nimport (\n")
+	for _, imp := range imports {
+		w.WriteString(fmt.Sprintf("\t%s\n", quoteString(imp)))
+	}
+	w.WriteString(")\n\nfunc main() {\n")
+	body := "nil"
+	switch {
+	case len(request.FormData) > 0:
+		body = "body"
+		w.WriteString("\tbody := &bytes.Buffer{}\n\twriter := multipart.NewWriter(body)\n")
+		for _, f := range request.FormData {
+			w.WriteString(fmt.Sprintf("\twriter.WriteField(%s, %s)\n", quoteString(f.Key), quoteString(f.Value)))
+		}
+		w.WriteString("\twriter.Close()\n")
+	case request.Body != "":
+		body = "body"
+		w.WriteString(fmt.Sprintf("\tbody := strings.NewReader(%s)\n", goQuote(request.Body)))
+	}
+	w.WriteString(fmt.Sprintf("\treq, err := http.NewRequest(%s, %s, %s)\n", quoteString(request.Method), quoteString(request.URL), body))
+	w.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, h := range request.Headers {
+		w.WriteString(fmt.Sprintf("\treq.Header.Set(%s, %s)\n", quoteString(h.Key), quoteString(h.Value)))
+	}
+	if len(request.FormData) > 0 {
+		w.WriteString(fmt.Sprintf("\treq.Header.Set(%s, writer.FormDataContentType())\n", quoteString(HEADER_NAME_CONTENT_TYPE)))
+	}
+	client := "http.DefaultClient"
+	if request.Proxy != "" {
+		client = "client"
+		w.WriteString(fmt.Sprintf("\tproxy, err := url.Parse(%s)\n", quoteString(request.Proxy)))
+		w.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+		w.WriteString("\tclient := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}\n")
+	}
+	w.WriteString(fmt.Sprintf("\tresp, err := %s.Do(req)\n", client))
+	w.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	w.WriteString("\tdefer resp.Body.Close()\n")
+	w.WriteString("\tb, err := io.ReadAll(resp.Body)\n")
+	w.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	w.WriteString("\tfmt.Println(resp.Status, string(b))\n}")
+	return w.String(), nil
+}
+
+func pythonSnippet(request SnippetRequest) (code string, err error) {
+	var w bytes.Buffer
+	w.WriteString("import requests\n\n")
+	w.WriteString(fmt.Sprintf("url = %s\n", quoteString(request.URL)))
+	args := []string{quoteString(request.Method), "url"}
+	if len(request.Headers) > 0 {
+		w.WriteString("headers = {\n")
+		for _, h := range request.Headers {
+			w.WriteString(fmt.Sprintf("    %s: %s,\n", quoteString(h.Key), quoteString(h.Value)))
+		}
+		w.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	switch {
+	case len(request.FormData) > 0:
+		w.WriteString("files = {\n")
+		for _, f := range request.FormData {
+			w.WriteString(fmt.Sprintf("    %s: (None, %s),\n", quoteString(f.Key), quoteString(f.Value)))
+		}
+		w.WriteString("}\n")
+		args = append(args, "files=files")
+	case request.Body != "":
+		w.WriteString(fmt.Sprintf("data = %s\n", quoteString(request.Body)))
+		args = append(args, `data=data.encode("utf-8")`)
+	}
+	if request.Proxy != "" {
+		w.WriteString(fmt.Sprintf("proxies = {\"http\": %s, \"https\": %s}\n", quoteString(request.Proxy), quoteString(request.Proxy)))
+		args = append(args, "proxies=proxies")
+	}
+	w.WriteString(fmt.Sprintf("\nresponse = requests.request(%s)\n", strings.Join(args, ", ")))
+	w.WriteString("print(response.status_code, response.text)")
+	return w.String(), nil
+}
+
+func phpSnippet(request SnippetRequest) (code string, err error) {
+	var w bytes.Buffer
+	w.WriteString("<?php\n$ch = curl_init();\ncurl_setopt_array($ch, [\n")
+	w.WriteString(fmt.Sprintf("    CURLOPT_URL => %s,\n", phpQuote(request.URL)))
+	w.WriteString(fmt.Sprintf("    CURLOPT_CUSTOMREQUEST => %s,\n", phpQuote(request.Method)))
+	w.WriteString("    CURLOPT_RETURNTRANSFER => true,\n")
+	if request.Proxy != "" {
+		w.WriteString(fmt.Sprintf("    CURLOPT_PROXY => %s,\n", phpQuote(request.Proxy)))
+	}
+	if len(request.Headers) > 0 {
+		w.WriteString("    CURLOPT_HTTPHEADER => [\n")
+		for _, h := range request.Headers {
+			w.WriteString(fmt.Sprintf("        %s,\n", phpQuote(fmt.Sprintf("%s: %s", h.Key, h.Value))))
+		}
+		w.WriteString("    ],\n")
+	}
+	switch {
+	case len(request.FormData) > 0: // 数组形式的 CURLOPT_POSTFIELDS 以 multipart/form-data 发送
+		w.WriteString("    CURLOPT_POSTFIELDS => [\n")
+		for _, f := range request.FormData {
+			w.WriteString(fmt.Sprintf("        %s => %s,\n", phpQuote(f.Key), phpQuote(f.Value)))
+		}
+		w.WriteString("    ],\n")
+	case request.Body != "":
+		w.WriteString(fmt.Sprintf("    CURLOPT_POSTFIELDS => %s,\n", phpQuote(request.Body)))
+	}
+	w.WriteString("]);\n$response = curl_exec($ch);\nif ($response === false) {\n    echo curl_error($ch);\n}\ncurl_close($ch);\necho $response;")
+	return w.String(), nil
+}
+
+// shellQuote 单引号包裹,内部单引号先闭合再转义
+func shellQuote(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", `'\''`))
+}
+
+func phpQuote(s string) string {
+	return fmt.Sprintf("'%s'", strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s))
+}
+
+// quoteString json 字符串格式,同时是 javascript、python、go 合法的字符串字面量
+func quoteString(s string) string {
+	var w bytes.Buffer
+	encoder := json.NewEncoder(&w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(w.String(), "\n")
+}
+
+// goQuote 优先使用反引号,保持 json 请求体可读
+func goQuote(s string) string {
+	if strings.Contains(s, "`") {
+		return quoteString(s)
+	}
+	return fmt.Sprintf("`%s`", s)
+}
@@ -0,0 +1,69 @@
+package apidocbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestApiSnippets(t *testing.T) {
+	api := openAPIService.Apis[0]
+	server := apidocbuilder.Server{URL: "http://test.api.com", Proxy: "http://127.0.0.1:8888"}
+	example := apidocbuilder.Example{
+		URL:         "http://dev.api.com/user/1/list?ids=1,2",
+		Headers:     map[string]string{"X-Token": "it's"},
+		RequestBody: `{"pageSize":10}`,
+	}
+	snippets, err := api.Snippets(server, example)
+	require.NoError(t, err)
+	require.Len(t, snippets, len(apidocbuilder.SnippetGeneratorsDefault))
+	for i, snippet := range snippets {
+		require.Equal(t, apidocbuilder.SnippetGeneratorsDefault[i].Language, snippet.Language)
+		require.Contains(t, snippet.Code, "http://test.api.com/user/1/list", snippet.Language)
+	}
+
+	curl := snippets[0].Code
+	require.Contains(t, curl, `curl -X POST 'http://test.api.com/user/1/list?ids=1,2'`)
+	require.Contains(t, curl, `-x 'http://127.0.0.1:8888'`)
+	require.Contains(t, curl, `-H 'Content-Type: application/json'`)
+	require.Contains(t, curl, `-H 'X-Token: it'\''s'`)
+	require.Contains(t, curl, `--data-raw '{"pageSize":10}'`)
+
+	fetch, err := api.Snippet("js", server, example)
+	require.NoError(t, err)
+	require.Contains(t, fetch.Code, `body: JSON.stringify({"pageSize":10})`)
+
+	goCode, err := api.Snippet("golang", server, example)
+	require.NoError(t, err)
+	require.Contains(t, goCode.Code, "strings.NewReader(`{\"pageSize\":10}`)")
+	require.Contains(t, goCode.Code, `http.ProxyURL(proxy)`)
+
+	_, err = api.Snippet("lua", server, example)
+	require.ErrorIs(t, err, apidocbuilder.ERROR_NOT_FOUND_SNIPPET_GENERATOR)
+}
+
+func TestApiSnippetsFromParameters(t *testing.T) {
+	api := apidocbuilder.Api{
+		Method:             "POST",
+		Path:               "/user/{id}",
+		RequestContentType: "multipart/form-data",
+		Query: apidocbuilder.Query{
+			{Fullname: "id", Type: "int", Example: "7", Position: "path"},
+			{Fullname: "lang", Type: "string", Example: "zh"},
+		},
+		RequestBody: apidocbuilder.Parameters{
+			{Fullname: "name", Type: "string", Example: "tom"},
+		},
+	}
+	snippet, err := api.Snippet("python", apidocbuilder.Server{URL: "http://api.com/"}, apidocbuilder.Example{})
+	require.NoError(t, err)
+	require.Contains(t, snippet.Code, `url = "http://api.com/user/7?lang=zh"`)
+	require.Contains(t, snippet.Code, `"name": (None, "tom"),`)
+	require.NotContains(t, snippet.Code, "Content-Type")
+
+	api.Service = &apidocbuilder.Service{Servers: apidocbuilder.Servers{{URL: "http://api.com"}}}
+	md, err := apidocbuilder.Api2Markdown(api)
+	require.NoError(t, err)
+	require.Contains(t, string(md), "```httpie\nhttp --multipart POST 'http://api.com/user/7?lang=zh'")
+}
@@ -0,0 +1,246 @@
+package apidocbuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	STATIC_SITE_INDEX        = "index.html"
+	STATIC_SITE_DIR_API      = "api"
+	STATIC_SITE_DIR_FORM     = "form"
+	STATIC_SITE_DIR_MARKDOWN = "markdown"
+	STATIC_SITE_DIR_ASSETS   = "assets"
+	STATIC_SITE_SEARCH_INDEX = "search.json"
+	STATIC_SITE_MARKDOWN_SRV = "service.md"
+)
+
+// staticDocumentRef 导出时使用的占位 DocumentRef,渲染后替换为相对路径
+const staticDocumentRef = "__apidocbuilder_static__"
+
+// staticAssetRef 内置资源的占位地址,渲染后替换为相对路径
+const staticAssetRef = "__apidocbuilder_assets__"
+
+// StaticFile 静态站点中的文件,Path 为相对站点根目录的路径
+type StaticFile struct {
+	Path    string
+	Content []byte
+}
+
+type StaticFiles []StaticFile
+
+// StaticSearchItem 搜索索引中的一个接口
+type StaticSearchItem struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Group       string `json:"group"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// StaticSite 生成静态站点:首页、每个接口一个页面、调试表单、markdown 源文件、内置前端资源及搜索索引,相同输入输出完全一致
+func StaticSite(service Service) (files StaticFiles, err error) {
+	return StaticSiteWithAssetBaseURL(service, "")
+}
+
+// StaticSiteWithAssetBaseURL assetBaseURL 不为空时前端资源从该地址加载,不再导出内置资源
+func StaticSiteWithAssetBaseURL(service Service, assetBaseURL string) (files StaticFiles, err error) {
+	site := newStaticSite(service)
+	site.assetBaseURL = assetBaseURL
+	files = make(StaticFiles, 0)
+	add := func(path string, base string, content []byte) {
+		files = append(files, StaticFile{Path: path, Content: site.rewriteLinks(content, base)})
+	}
+
+	index, err := RenderService(site.render(), "")
+	if err != nil {
+		return nil, err
+	}
+	add(STATIC_SITE_INDEX, "", index)
+	serviceMarkdown, err := Service2Markdown(site.serviceWithApiRefs())
+	if err != nil {
+		return nil, err
+	}
+	add(fmt.Sprintf("%s/%s", STATIC_SITE_DIR_MARKDOWN, STATIC_SITE_MARKDOWN_SRV), "../", serviceMarkdown)
+
+	for _, api := range site.service.Apis {
+		page, err := RenderService(site.render(), api.Name)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "render api page %s", api.Name)
+		}
+		add(site.apiPath(api.Name), "../", page)
+
+		form, err := RenderForm(site.render(), api.Name)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "render form %s", api.Name)
+		}
+		add(site.formPath(api.Name), "../", form)
+
+		markdown, err := Api2Markdown(api)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "render markdown %s", api.Name)
+		}
+		add(site.markdownPath(api.Name), "../", markdown)
+	}
+
+	if assetBaseURL == "" {
+		for _, asset := range AssetsDefault {
+			content, err := asset.Content()
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, StaticFile{Path: fmt.Sprintf("%s/%s", STATIC_SITE_DIR_ASSETS, asset.Name), Content: content})
+		}
+	}
+
+	searchIndex, err := json.MarshalIndent(site.searchIndex(), "", "  ")
+	if err != nil {
+		return nil, errors.WithMessage(err, "marshal search index")
+	}
+	files = append(files, StaticFile{Path: STATIC_SITE_SEARCH_INDEX, Content: searchIndex})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// ExportStaticSite 生成静态站点并写入 dir 目录
+func ExportStaticSite(service Service, dir string) (err error) {
+	files, err := StaticSite(service)
+	if err != nil {
+		return err
+	}
+	return files.WriteTo(dir)
+}
+
+// WriteTo 写入目录,已存在的同名文件覆盖
+func (files StaticFiles) WriteTo(dir string) (err error) {
+	for _, file := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err = os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return errors.WithMessagef(err, "mkdir %s", filepath.Dir(filename))
+		}
+		if err = os.WriteFile(filename, file.Content, 0o644); err != nil {
+			return errors.WithMessagef(err, "write %s", filename)
+		}
+	}
+	return nil
+}
+
+type staticSite struct {
+	service      Service
+	assetBaseURL string
+	fileNames    map[string]string // 接口名称 => 文件名
+}
+
+func newStaticSite(service Service) (site *staticSite) {
+	apis := make(Apis, len(service.Apis))
+	copy(apis, service.Apis)
+	service.Apis = apis
+	service.DocumentRef = staticDocumentRef
+	site = &staticSite{service: service}
+	for i := range site.service.Apis {
+		site.service.Apis[i].DocumentRef = staticDocumentRef
+		site.service.Apis[i].Service = &site.service
+	}
+	site.fileNames = staticFileNames(site.service.Apis)
+	return site
+}
+
+// render 静态站点没有调试代理,表单直接请求服务器地址
+func (site *staticSite) render() ServiceRender {
+	return ServiceRender{Service: site.service, DisableDebugProxy: true, AssetBaseURL: site.assetBaseURL, localAssetBaseURL: staticAssetRef}
+}
+
+// serviceWithApiRefs 服务 markdown 中接口链接指向各自的页面
+func (site *staticSite) serviceWithApiRefs() (service Service) {
+	service = site.service
+	service.Apis = make(Apis, len(site.service.Apis))
+	for i, api := range site.service.Apis {
+		api.DocumentRef = fmt.Sprintf("%s?%s=%s", staticDocumentRef, DOC_QUERY_NAME, api.Name)
+		service.Apis[i] = api
+	}
+	return service
+}
+
+func (site *staticSite) apiPath(name string) string {
+	return fmt.Sprintf("%s/%s.html", STATIC_SITE_DIR_API, site.fileNames[name])
+}
+
+func (site *staticSite) formPath(name string) string {
+	return fmt.Sprintf("%s/%s.html", STATIC_SITE_DIR_FORM, site.fileNames[name])
+}
+
+func (site *staticSite) markdownPath(name string) string {
+	return fmt.Sprintf("%s/%s.md", STATIC_SITE_DIR_MARKDOWN, site.fileNames[name])
+}
+
+// rewriteLinks 将 DocumentRef、GetFormPathWithQuery 生成的链接替换为相对路径,base 为当前文件到站点根目录的相对路径
+func (site *staticSite) rewriteLinks(content []byte, base string) []byte {
+	pairs := make([]string, 0)
+	for _, api := range site.service.Apis {
+		pairs = append(pairs,
+			fmt.Sprintf("%s?%s=%s\"", getFormPath(staticDocumentRef), DOC_QUERY_NAME, api.Name), base+site.formPath(api.Name)+"\"",
+			fmt.Sprintf("%s?%s=%s)", getFormPath(staticDocumentRef), DOC_QUERY_NAME, api.Name), base+site.formPath(api.Name)+")",
+			fmt.Sprintf("%s?%s=%s\"", staticDocumentRef, DOC_QUERY_NAME, api.Name), base+site.apiPath(api.Name)+"\"",
+			fmt.Sprintf("%s?%s=%s)", staticDocumentRef, DOC_QUERY_NAME, api.Name), base+site.apiPath(api.Name)+")",
+		)
+	}
+	pairs = append(pairs, staticAssetRef, base+STATIC_SITE_DIR_ASSETS, staticDocumentRef, base+STATIC_SITE_INDEX)
+	return []byte(strings.NewReplacer(pairs...).Replace(string(content)))
+}
+
+func (site *staticSite) searchIndex() (items []StaticSearchItem) {
+	items = make([]StaticSearchItem, 0, len(site.service.Apis))
+	for _, api := range site.service.Apis {
+		items = append(items, StaticSearchItem{
+			Name:        api.Name,
+			Title:       api.TitleOrDescription(),
+			Group:       api.Group,
+			Method:      strings.ToUpper(api.Method),
+			Path:        api.Path,
+			Description: api.Description,
+			URL:         site.apiPath(api.Name),
+		})
+	}
+	return items
+}
+
+var staticFileNameReg = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// staticFileName 接口名称转换为文件名
+func staticFileName(name string) string {
+	name = staticFileNameReg.ReplaceAllString(name, "_")
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// staticFileNames 不同接口名称可能转换为同一文件名(如 user/list 与 user_list,或仅大小写不同),后出现的加名称摘要后缀,避免页面互相覆盖
+func staticFileNames(apis Apis) (fileNames map[string]string) {
+	fileNames = make(map[string]string, len(apis))
+	used := make(map[string]bool)
+	for _, api := range apis {
+		if _, ok := fileNames[api.Name]; ok {
+			continue
+		}
+		fileName := staticFileName(api.Name)
+		if used[strings.ToLower(fileName)] {
+			sum := sha256.Sum256([]byte(api.Name))
+			fileName = fmt.Sprintf("%s_%s", fileName, hex.EncodeToString(sum[:4]))
+		}
+		used[strings.ToLower(fileName)] = true
+		fileNames[api.Name] = fileName
+	}
+	return fileNames
+}
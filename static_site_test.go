@@ -0,0 +1,126 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestStaticSite(t *testing.T) {
+	service := newEnvironmentService()
+	service.AddApi(apidocbuilder.Api{Name: "addUser", Title: "新增用户", Group: "用户", Method: "POST", Path: "/user/add", RequestBody: apidocbuilder.Parameters{{Fullname: "name", Type: "string", Required: true}}})
+
+	files, err := apidocbuilder.StaticSite(*service)
+	require.NoError(t, err)
+	paths := make([]string, 0, len(files))
+	contents := make(map[string]string)
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+		paths = append(paths, file.Path)
+	}
+	require.Equal(t, []string{
+		"api/addUser.html",
+		"api/getUser.html",
+		"assets/alpine.min.js",
+		"assets/htmx.min.js",
+		"assets/prism-json.min.js",
+		"assets/prism.min.css",
+		"assets/prism.min.js",
+		"form/addUser.html",
+		"form/getUser.html",
+		"index.html",
+		"markdown/addUser.md",
+		"markdown/getUser.md",
+		"markdown/service.md",
+		"search.json",
+	}, paths)
+
+	require.Contains(t, contents["index.html"], `href="api/addUser.html"`)
+	require.Contains(t, contents["api/addUser.html"], `href="../api/getUser.html"`)
+	require.Contains(t, contents["api/addUser.html"], `href="../form/addUser.html"`)
+	require.Contains(t, contents["markdown/addUser.md"], "[在线调试](../form/addUser.html)")
+	require.Contains(t, contents["markdown/service.md"], "[新增用户](../api/addUser.html)")
+	require.Contains(t, contents["form/addUser.html"], `hx-post="https://api.example.com/user/add"`)
+	require.Contains(t, contents["form/addUser.html"], `src="../assets/htmx.min.js?v=1.9.12"`)
+	for path, content := range contents {
+		require.NotContains(t, content, "__apidocbuilder_static__", path)
+	}
+
+	var searchIndex []apidocbuilder.StaticSearchItem
+	require.NoError(t, json.Unmarshal([]byte(contents["search.json"]), &searchIndex))
+	require.Len(t, searchIndex, 2)
+	require.Equal(t, apidocbuilder.StaticSearchItem{Name: "addUser", Title: "新增用户", Group: "用户", Method: "POST", Path: "/user/add", URL: "api/addUser.html"}, searchIndex[1])
+
+	again, err := apidocbuilder.StaticSite(*service)
+	require.NoError(t, err)
+	require.Equal(t, files, again)
+
+	// 动态变量不在导出时计算,保证每次导出内容一致
+	dynamic := *service
+	dynamic.Apis = append(apidocbuilder.Apis{}, service.Apis...)
+	dynamic.AddApi(apidocbuilder.Api{Name: "track", Method: "POST", Path: "/track",
+		RequestHeader: apidocbuilder.Header{{Fullname: "X-Request-Id", Default: "{{$uuid}}"}},
+		Examples:      []*apidocbuilder.Example{{RequestBody: `{"ts":"{{$timestamp}}"}`}},
+	})
+	dynamicFiles, err := apidocbuilder.StaticSite(dynamic)
+	require.NoError(t, err)
+	dynamicAgain, err := apidocbuilder.StaticSite(dynamic)
+	require.NoError(t, err)
+	require.Equal(t, dynamicFiles, dynamicAgain)
+	trackMarkdown := ""
+	for _, file := range dynamicFiles {
+		if file.Path == "markdown/track.md" {
+			trackMarkdown = string(file.Content)
+		}
+	}
+	require.Contains(t, trackMarkdown, "{{$uuid}}")
+	require.Contains(t, trackMarkdown, "{{$timestamp}}")
+
+	custom, err := apidocbuilder.StaticSiteWithAssetBaseURL(*service, "https://cdn.example.com/apidoc")
+	require.NoError(t, err)
+	for _, file := range custom {
+		require.NotContains(t, file.Path, "assets/")
+		if file.Path == "form/addUser.html" {
+			require.Contains(t, string(file.Content), `src="https://cdn.example.com/apidoc/htmx.min.js"`)
+		}
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, apidocbuilder.ExportStaticSite(*service, dir))
+	b, err := os.ReadFile(filepath.Join(dir, "form", "getUser.html"))
+	require.NoError(t, err)
+	require.Equal(t, contents["form/getUser.html"], string(b))
+}
+
+func TestStaticSiteFileNameCollision(t *testing.T) {
+	service := apidocbuilder.Service{}
+	service.AddApi(
+		apidocbuilder.Api{Name: "user/list", Title: "用户列表", Method: "GET", Path: "/user/list"},
+		apidocbuilder.Api{Name: "user_list", Title: "用户列表(旧)", Method: "GET", Path: "/v1/user/list"},
+		apidocbuilder.Api{Name: "User_List", Title: "用户列表(兼容)", Method: "GET", Path: "/v0/user/list"},
+	)
+	files, err := apidocbuilder.StaticSite(service)
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+	var searchIndex []apidocbuilder.StaticSearchItem
+	require.NoError(t, json.Unmarshal([]byte(contents["search.json"]), &searchIndex))
+	require.Len(t, searchIndex, 3)
+	urls := make(map[string]bool)
+	for _, item := range searchIndex {
+		require.False(t, urls[strings.ToLower(item.URL)], item.URL)
+		urls[strings.ToLower(item.URL)] = true
+		require.Contains(t, contents[item.URL], item.Title, item.Name)
+		require.Contains(t, contents["index.html"], `href="`+item.URL+`"`, item.Name)
+	}
+	require.Equal(t, "api/user_list.html", searchIndex[0].URL)
+	require.Regexp(t, `^api/user_list_[0-9a-f]{8}\.html$`, searchIndex[1].URL)
+	require.Regexp(t, `^api/User_List_[0-9a-f]{8}\.html$`, searchIndex[2].URL)
+}
@@ -5,10 +5,14 @@ import (
 	"embed"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
@@ -17,8 +21,22 @@ import (
 //go:embed  template
 var TemplateFS embed.FS
 
+// templateFuncs sprig 函数及 markdownCell
+func templateFuncs() template.FuncMap {
+	funcs := sprig.FuncMap()
+	funcs["markdownCell"] = markdownCell
+	return funcs
+}
+
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// markdownCell 转义 markdown 表格单元格中的 | 和换行,避免 json 值等内容破坏表格
+func markdownCell(s string) string {
+	return markdownCellReplacer.Replace(s)
+}
+
 func ExecTpl(tplName string, data any) (content []byte, err error) {
-	tpl, err := template.New("").Funcs(sprig.FuncMap()).ParseFS(TemplateFS, "template/*.tpl")
+	tpl, err := template.New("").Funcs(templateFuncs()).ParseFS(TemplateFS, "template/*.tpl")
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +53,7 @@ func ExecTpl(tplName string, data any) (content []byte, err error) {
 var HtmlTemplateFS embed.FS
 
 func newTplInstance() *template.Template {
-	return template.New("").Funcs(sprig.FuncMap())
+	return template.New("").Funcs(templateFuncs())
 }
 
 func RenderHtml(tplInstance *template.Template, filename string, data any) (content []byte, err error) {
@@ -63,9 +81,13 @@ func RenderHtml(tplInstance *template.Template, filename string, data any) (cont
 }
 
 const (
-	TPL_NAME_MARKDOWN_DOC     = "markdownDoc"
-	TPL_NAME_MARKDOWN_SERVICE = "markdownService"
-	TPL_NAME_HTML_DEBUGGING   = "debugging"
+	TPL_NAME_MARKDOWN_DOC       = "markdownDoc"
+	TPL_NAME_MARKDOWN_SERVICE   = "markdownService"
+	TPL_NAME_MARKDOWN_CONTRACT  = "markdownContract"
+	TPL_NAME_MARKDOWN_DIFF      = "markdownDiff"
+	TPL_NAME_MARKDOWN_CHANGELOG = "markdownChangelog"
+	TPL_NAME_MARKDOWN_LINT      = "markdownLint"
+	TPL_NAME_HTML_DEBUGGING     = "debugging"
 )
 
 func Api2Markdown(api Api) (out []byte, err error) {
@@ -83,9 +105,23 @@ func Service2Markdown(service Service) (out []byte, err error) {
 	return out, nil
 }
 
+// MarkdownHighlightStyle 代码块服务端高亮使用的 chroma 样式
+var MarkdownHighlightStyle = "github"
+
+// Markdown2HTML 代码块在服务端高亮(行内样式),页面无需 js
 func Markdown2HTML(markdownContent []byte) (out []byte, err error) {
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(MarkdownHighlightStyle),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(false)),
+				highlighting.WithCodeBlockOptions(func(c highlighting.CodeBlockContext) []chromahtml.Option {
+					language, _ := c.Language()
+					return []chromahtml.Option{chromahtml.WithPreWrapper(languagePreWrapper(language))}
+				}),
+			),
+		),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
@@ -107,9 +143,58 @@ func Markdown2HTML(markdownContent []byte) (out []byte, err error) {
 	return out, nil
 }
 
+// languagePreWrapper 保留 code 标签的 language-xxx 类名,html_doc.html 中按语言切换代码示例依赖该类名
+type languagePreWrapper []byte
+
+func (language languagePreWrapper) Start(code bool, styleAttr string) string {
+	if !code {
+		return fmt.Sprintf(`<pre%s>`, styleAttr)
+	}
+	if len(language) == 0 {
+		return fmt.Sprintf(`<pre%s><code>`, styleAttr)
+	}
+	return fmt.Sprintf(`<pre%s><code class="language-%s">`, styleAttr, template.HTMLEscapeString(string(language)))
+}
+
+func (language languagePreWrapper) End(code bool) string {
+	if !code {
+		return `</pre>`
+	}
+	return `</code></pre>`
+}
+
 type ServiceRender struct {
 	Service
-	activeApi *Api `json:"-"`
+	// 调试表单使用的环境,为空时使用默认环境
+	Environment string
+	// 静态站点等无服务端的场景,调试表单直接请求服务器地址
+	DisableDebugProxy bool
+	// 前端资源(htmx、alpine、prism)地址前缀,为空时使用内置资源
+	AssetBaseURL string
+	// 前端资源使用公共 CDN 地址,AssetBaseURL 为空时生效
+	AssetCDN bool
+	// 变更记录页面地址,不为空时导航栏展示入口
+	ChangelogRef string
+	// 搜索框中的查询词,不为空时导航栏只展示按相关度排序的搜索结果
+	SearchQuery string
+	// 内置资源的访问地址,由 DocHandler、静态站点设置
+	localAssetBaseURL string
+	activeApi         *Api `json:"-"`
+}
+
+// SearchApis 导航栏中的搜索结果
+func (s ServiceRender) SearchApis() (apis Apis) {
+	return s.Apis.Search(s.SearchQuery)
+}
+
+// SearchHref 搜索结果中的链接保留查询词
+func (s ServiceRender) SearchHref(api Api) (href string) {
+	query := url.Values{DOC_QUERY_NAME: []string{api.Name}, DOC_QUERY_SEARCH: []string{s.SearchQuery}}
+	return fmt.Sprintf("%s?%s", s.DocumentRef, query.Encode())
+}
+
+func (s ServiceRender) assetLinker() AssetLinker {
+	return AssetLinker{BaseURL: s.AssetBaseURL, CDN: s.AssetCDN, local: s.localAssetBaseURL}
 }
 
 func (s *ServiceRender) SetActiveApi(api Api) { // 渲染html时使用
@@ -172,6 +257,15 @@ func RenderService(serviceRender ServiceRender, currentApiName string) (out []by
 type FormView struct {
 	Title string
 	Form  string
+	// 可切换的环境
+	Environments []FormEnvironment
+	Assets       AssetLinker
+}
+
+type FormEnvironment struct {
+	Name     string
+	Title    string
+	Selected bool
 }
 
 func RenderForm(serviceRender ServiceRender, currentApiName string) (out []byte, err error) {
@@ -184,10 +278,31 @@ func RenderForm(serviceRender ServiceRender, currentApiName string) (out []byte,
 	}
 
 	filename := "html_form.html"
-	htmxForm := NewHtmxForm(*serviceRender.activeApi)
+	htmxForm := NewHtmxForm(*serviceRender.GetActiveApi())
+	if serviceRender.Environment != "" || serviceRender.DisableDebugProxy {
+		htmxForm, err = NewHtmxFormWithEnvironment(*serviceRender.GetActiveApi(), serviceRender.Environment)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !serviceRender.DisableDebugProxy {
+		// 表单提交到调试代理,由服务端转发到所选环境,避免跨域并使用服务器配置的http代理
+		proxyQuery := url.Values{DOC_QUERY_NAME: []string{serviceRender.GetActiveApi().Name}, DOC_QUERY_ENVIRONMENT: []string{htmxForm.Environment}}
+		htmxForm.Action = fmt.Sprintf("%s?%s", getProxyPath(serviceRender.DocumentRef), proxyQuery.Encode())
+	}
 	formView := FormView{
-		Title: htmxForm.Title,
-		Form:  htmxForm.String(),
+		Title:  htmxForm.Title,
+		Form:   htmxForm.String(),
+		Assets: serviceRender.assetLinker(),
+	}
+	if len(serviceRender.Servers) > 1 && !serviceRender.DisableDebugProxy { // 切换环境需要服务端重新渲染
+		for _, server := range serviceRender.Servers {
+			formView.Environments = append(formView.Environments, FormEnvironment{
+				Name:     server.GetEnvironment(),
+				Title:    firstNotEmpty(server.Title, server.GetEnvironment()),
+				Selected: strings.EqualFold(server.GetEnvironment(), htmxForm.Environment),
+			})
+		}
 	}
 	out, err = RenderHtml(newTplInstance(), filename, formView)
 	if err != nil {
@@ -0,0 +1,22 @@
+{{- define "markdownChangelog" -}}
+# {{.Service}} 变更记录
+{{range $version:= .Versions}}
+## {{$version.Version}}
+
+{{$breaking:= $version.Changes.Breaking -}}
+对比 {{$version.PreviousVersion}}{{if $breaking}},**不兼容变更 {{len $breaking}} 项**{{end}}
+{{if not $version.Groups}}
+无接口变更
+{{end}}
+{{- range $group:= $version.Groups}}
+### {{if $group.Name}}{{$group.Name}}{{else}}未分组{{end}}
+
+{{range $api:= $group.Apis -}}
+- {{if $api.DocumentRef}}[{{$api.Title}}]({{$api.DocumentRef}}){{else}}{{$api.Title}}{{end}} `{{$api.Method}} {{$api.Path}}`
+{{- range $change:= $api.Changes}}
+  - {{if $change.Breaking}}**[不兼容]** {{end}}{{if $change.Fullname}}{{$change.Position}} `{{$change.Fullname}}`: {{end}}{{$change.Message}}
+{{- end}}
+{{end}}
+{{- end}}
+{{- end}}
+{{end}}
@@ -0,0 +1,45 @@
+{{- define "markdownContract" -}}
+{{- $total:=0 -}}{{- $failed:=0 -}}{{- $skipped:=0 -}}
+{{- range $result:= .Results -}}
+{{- $total = add $total 1 -}}
+{{- if $result.Skipped -}}{{- $skipped = add $skipped 1 -}}{{- else if not $result.Passed -}}{{- $failed = add $failed 1 -}}{{- end -}}
+{{- end -}}
+# {{.Service}} 契约测试报告
+
+**结果:** {{if eq $failed 0}}通过{{else}}失败{{end}}
+
+|总数|失败|跳过|耗时|
+|:--|:--|:--|:--|
+|{{$total}}|{{$failed}}|{{$skipped}}|{{.Duration}}|
+
+|结果|接口|案例|状态码|耗时|
+|:--|:--|:--|:--|:--|
+{{range $result:= .Results -}}
+|{{if $result.Skipped}}跳过{{else if $result.Passed}}通过{{else}}**失败**{{end}}|{{$result.Method}} {{$result.Path}}|{{markdownCell $result.Example}}|{{$result.Status}}|{{$result.Duration}}|
+{{end}}
+{{- range $result:= .Results -}}
+{{- if and (not $result.Skipped) (or (not $result.Passed) $result.Diffs $result.Assertions) }}
+
+### {{$result.Name}}
+
+- ***请求:*** {{$result.URL}}
+{{range $failure:= $result.Failures -}}
+- {{$failure}}
+{{end}}
+{{- if $result.Assertions}}
+|断言|结果|说明|
+|:--|:--|:--|
+{{range $assertion:= $result.Assertions -}}
+|{{markdownCell $assertion.Name}}|{{if $assertion.Passed}}通过{{else}}**失败**{{end}}|{{markdownCell $assertion.Message}}|
+{{end}}
+{{- end}}
+{{- if $result.Diffs}}
+|路径|差异|期望|实际|失败|
+|:--|:--|:--|:--|:--|
+{{range $diff:= $result.Diffs -}}
+|{{markdownCell $diff.Path}}|{{$diff.Kind}}|{{markdownCell $diff.Expected}}|{{markdownCell $diff.Actual}}|{{$diff.Fail}}|
+{{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}
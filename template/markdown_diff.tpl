@@ -0,0 +1,31 @@
+{{- define "markdownDiff" -}}
+{{- $breaking:= .Breaking -}}
+{{- $nonBreaking:= .NonBreaking -}}
+# {{.Service}} 接口变更{{if or .OldVersion .NewVersion}} {{.OldVersion}} -> {{.NewVersion}}{{end}}
+
+**结果:** {{if $breaking}}存在不兼容变更{{else if $nonBreaking}}兼容{{else}}无变更{{end}}
+
+|不兼容|兼容|
+|:--|:--|
+|{{len $breaking}}|{{len $nonBreaking}}|
+{{- if $breaking}}
+
+## 不兼容变更
+
+|接口|位置|参数|变更|说明|
+|:--|:--|:--|:--|:--|
+{{range $change:= $breaking -}}
+|{{$change.Method}} {{$change.Path}}|{{$change.Position}}|{{markdownCell $change.Fullname}}|{{$change.Action}}{{if $change.Field}} {{$change.Field}}{{end}}|{{markdownCell $change.Message}}|
+{{end}}
+{{- end}}
+{{- if $nonBreaking}}
+
+## 兼容变更
+
+|接口|位置|参数|变更|说明|
+|:--|:--|:--|:--|:--|
+{{range $change:= $nonBreaking -}}
+|{{$change.Method}} {{$change.Path}}|{{$change.Position}}|{{markdownCell $change.Fullname}}|{{$change.Action}}{{if $change.Field}} {{$change.Field}}{{end}}|{{markdownCell $change.Message}}|
+{{end}}
+{{- end}}
+{{end}}
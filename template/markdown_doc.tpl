@@ -27,36 +27,21 @@
 {{if .RequestHeader }}
 
 **请求Header头**
-|参数名|类型|格式|必选|可空|标题|说明|默认值|示例|
-|:---|:---|:---|:---|:---|:---|:---|:---|:---|
-{{range $param:= .RequestHeader -}}
-{{- $format:=$param.GetFormat -}}
-|{{$param.Fullname}}|{{$param.Type}}|{{$format.String}}|{{$param.Required}}|{{$param.AllowEmptyValue}}|{{$param.Title}}|{{$param.Description}}|{{$param.Default}}|{{$param.Example}}|
-{{end}}
+{{template "markdownParameters" .RequestHeader.Rows}}
 
 {{- end}}
 
 {{if .Query -}}
 
 **请求Query**
-|参数名|类型|格式|必选|可空|标题|说明|默认值|示例|
-|:---|:---|:---|:---|:---|:---|:---|:---|:---|
-{{range $param:= .Query -}}
-{{- $format:=$param.GetFormat -}}
-|{{$param.Fullname}}|{{$param.Type}}|{{$format.String}}|{{$param.Required}}|{{$param.AllowEmptyValue}}|{{$param.Title}}|{{$param.Description}}|{{$param.Default}}|{{$param.Example}}|
-{{end}}
+{{template "markdownParameters" .Query.Rows}}
 
 {{- end}}
 
 {{if .RequestBody -}}
 
 **请求Body**
-|参数名|类型|格式|必选|可空|标题|说明|默认值|示例|
-|:---|:---|:---|:---|:---|:---|:---|:---|:---|
-{{range $param:= .RequestBody -}}
-{{- $format:=$param.GetFormat -}}
-|{{$param.Fullname}}|{{$param.Type}}|{{$format.String}}|{{$param.Required}}|{{$param.AllowEmptyValue}}|{{$param.Title}}|{{$param.Description}}|{{$param.Default}}|{{$param.Example}}|
-{{end}}
+{{template "markdownParameters" .RequestBody.Rows}}
 
 **请求案例**
 ```json
@@ -66,6 +51,18 @@
 
 {{- end}}
 
+{{$snippets:=.GetSnippets -}}
+{{if $snippets -}}
+
+**请求代码**
+{{range $snippet:= $snippets}}
+```{{$snippet.Language}}
+{{$snippet.Code}}
+```
+{{end}}
+
+{{- end}}
+
 ### 响应
 
 ***响应格式：*** {{.ResponseContentType}}
@@ -73,24 +70,14 @@
 {{if .ResponseHeader }}
 
 **响应Header头参数**
-|参数名|类型|格式|必选|可空|标题|说明|默认值|示例|
-|:---|:---|:---|:---|:---|:---|:---|:---|:---|
-{{range $param:= .ResponseHeader -}}
-{{- $format:=$param.GetFormat -}}
-|{{$param.Fullname}}|{{$param.Type}}|{{$format.String}}|{{$param.Required}}|{{$param.AllowEmptyValue}}|{{$param.Title}}|{{$param.Description}}|{{$param.Default}}|{{$param.Example}}|
-{{end}}
+{{template "markdownParameters" .ResponseHeader.Rows}}
 
 {{- end}}
 
 {{if .ResponseBody -}}
 
 **响应Body参数**
-|参数名|类型|格式|标题|说明|示例|
-|:---|:---|:---|:---|:---|:---|
-{{range $param:= .ResponseBody -}}
-{{- $format:=$param.GetFormat -}}
-|{{$param.Fullname}}|{{$param.Type}}|{{$format.String}}|{{$param.Title}}|{{$param.Description}}|{{$param.Example}}|
-{{end}}
+{{template "markdownParameters" .ResponseBody.Rows}}
 
 **响应案例**
 ```json
@@ -101,4 +88,13 @@
 {{- end}}
 
 **备注** 
-{{end}}
\ No newline at end of file
+{{end}}
+
+{{- define "markdownParameters" -}}
+|参数名|类型|格式|必选|可空|标题|说明|枚举|约束|默认值|示例|
+|:---|:---|:---|:---|:---|:---|:---|:---|:---|:---|:---|
+{{range $row:= . -}}
+{{- $format:=$row.GetFormat -}}
+|{{$row.IndentedName}}|{{$row.Type}}|{{$format.String}}|{{$row.Required}}|{{$row.AllowEmptyValue}}|{{$row.Title}}|{{$row.Description}}|{{$row.EnumLabels}}|{{$row.Constraints}}|{{$row.Default}}|{{$row.Example}}|
+{{end}}
+{{- end}}
@@ -0,0 +1,17 @@
+{{- define "markdownLint" -}}
+# {{.Service}} 文档检查报告
+
+**结果:** {{if .Passed}}通过{{else}}失败{{end}}
+
+|错误|警告|提示|
+|:--|:--|:--|
+|{{.Findings.Count "error"}}|{{.Findings.Count "warning"}}|{{.Findings.Count "info"}}|
+{{- if .Findings}}
+
+|级别|规则|位置|说明|
+|:--|:--|:--|:--|
+{{range $finding:= .Findings -}}
+|{{$finding.Severity}}|{{$finding.Rule}}|{{if $finding.Location}}{{markdownCell $finding.Location}}{{else}}服务{{end}}|{{markdownCell $finding.Message}}|
+{{end}}
+{{- end}}
+{{end}}
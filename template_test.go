@@ -55,3 +55,13 @@ func TestServiceHtml(t *testing.T) {
 	s := string(htm)
 	fmt.Println(s)
 }
+
+func TestMarkdown2HTMLHighlight(t *testing.T) {
+	md := "```json\n{\"id\":1}\n```\n\n```httpie\nhttp GET api.com\n```\n"
+	htm, err := apidocbuilder.Markdown2HTML([]byte(md))
+	require.NoError(t, err)
+	s := string(htm)
+	require.Contains(t, s, `<code class="language-json"><span`)
+	require.Contains(t, s, `style="`)
+	require.Contains(t, s, `<code class="language-httpie">`)
+}
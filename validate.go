@@ -0,0 +1,381 @@
+package apidocbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+const (
+	VALIDATION_RULE_REQUIRED   = "required"
+	VALIDATION_RULE_TYPE       = "type"
+	VALIDATION_RULE_ENUM       = "enum"
+	VALIDATION_RULE_PATTERN    = "pattern"
+	VALIDATION_RULE_MIN_LENGTH = "minLength"
+	VALIDATION_RULE_MAX_LENGTH = "maxLength"
+	VALIDATION_RULE_MINIMUM    = "minimum"
+	VALIDATION_RULE_MAXIMUM    = "maximum"
+	VALIDATION_RULE_MIN_ITEMS  = "minItems"
+	VALIDATION_RULE_MAX_ITEMS  = "maxItems"
+	VALIDATION_RULE_JSON       = "json"
+)
+
+const (
+	VALIDATION_POSITION_BODY = "body"
+)
+
+// ValidationError 单个参数违反的单条规则
+type ValidationError struct {
+	// 参数全称,与文档中的 Parameter.Fullname 一致
+	Fullname string `json:"fullname"`
+	// 数据中的实际位置,数组带下标,如 items[1].name
+	Path     string `json:"path"`
+	Position string `json:"position"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, fmt.Sprintf("%s %s: %s", e.Position, e.Path, e.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (errs *ValidationErrors) add(p Parameter, path string, position string, rule string, format string, args ...any) {
+	*errs = append(*errs, ValidationError{Fullname: p.Fullname, Path: path, Position: position, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// RequestValidator 根据 Service 中的接口定义校验请求,文档与接口共用一份参数约束
+type RequestValidator struct {
+	service Service
+	// 校验失败时的响应,默认返回 400 和 json 格式的错误列表
+	OnError func(w http.ResponseWriter, r *http.Request, errs ValidationErrors)
+	// 请求体最大字节数,超出时返回 413;小于等于0时不限制
+	MaxBodySize int64
+}
+
+func NewRequestValidator(service Service) (validator *RequestValidator) {
+	return &RequestValidator{service: service, OnError: writeValidationErrors, MaxBodySize: 10 << 20}
+}
+
+// Middleware 校验通过后调用 next,未定义的接口直接放行
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api, pathParams, err := v.service.Apis.Match(r.Method, r.URL.Path)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if v.MaxBodySize > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, v.MaxBodySize)
+		}
+		errs, err := api.ValidateRequest(r, pathParams)
+		if err != nil {
+			status := http.StatusBadRequest
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if len(errs) > 0 {
+			v.OnError(w, r, errs)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeValidationErrors(w http.ResponseWriter, r *http.Request, errs ValidationErrors) {
+	w.Header().Set(HEADER_NAME_CONTENT_TYPE, Header_Value_Content_Type_Json)
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"code":    http.StatusBadRequest,
+		"message": "request validation failed",
+		"errors":  errs,
+	})
+}
+
+// ValidateRequest 校验 query、path、header、cookie 和请求体,请求体读取后会重新写回 r.Body
+func (api Api) ValidateRequest(r *http.Request, pathParams map[string]string) (errs ValidationErrors, err error) {
+	errs = make(ValidationErrors, 0)
+	query := r.URL.Query()
+	for _, p := range api.Query {
+		p.FormatField()
+		var values []string
+		switch p.Position {
+		case OpenAPI_In_Path:
+			if value, ok := pathParams[p.Fullname]; ok {
+				values = []string{value}
+			}
+		case OpenAPI_In_Header:
+			values = r.Header.Values(p.Name)
+		case OpenAPI_In_Cookie:
+			if cookie, err := r.Cookie(p.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			values = query[p.Name]
+			if len(values) == 0 {
+				values = query[p.Fullname]
+			}
+		}
+		position := p.Position
+		if position == "" {
+			position = OpenAPI_In_Query
+		}
+		errs.validateStrings(p, values, position)
+	}
+	for _, p := range api.RequestHeader {
+		p.FormatField()
+		errs.validateStrings(p, r.Header.Values(p.Name), OpenAPI_In_Header)
+	}
+	if len(api.RequestBody) == 0 || r.Body == nil {
+		return errs, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	contentType := strings.ToLower(r.Header.Get(HEADER_NAME_CONTENT_TYPE))
+	if strings.Contains(contentType, "x-www-form-urlencoded") || strings.Contains(contentType, "multipart/form-data") {
+		err = r.ParseMultipartForm(32 << 20)
+		if err != nil && err != http.ErrNotMultipart {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		for _, p := range api.RequestBody {
+			p.FormatField()
+			errs.validateStrings(p, r.PostForm[p.Fullname], VALIDATION_POSITION_BODY)
+		}
+		return errs, nil
+	}
+	errs = append(errs, api.RequestBody.ValidateJson(body)...)
+	return errs, nil
+}
+
+// ValidateJson 校验json数据,返回所有不满足约束的参数
+func (ps Parameters) ValidateJson(data []byte) (errs ValidationErrors) {
+	errs = make(ValidationErrors, 0)
+	var value any = map[string]any{} // 空请求体按空对象校验必填
+	if len(bytes.TrimSpace(data)) > 0 {
+		err := json.Unmarshal(data, &value)
+		if err != nil {
+			errs = append(errs, ValidationError{Position: VALIDATION_POSITION_BODY, Rule: VALIDATION_RULE_JSON, Message: err.Error()})
+			return errs
+		}
+	}
+	for _, p := range ps {
+		p.FormatField()
+		for _, found := range lookupJsonValues(value, parseFullname(p.Fullname), "") {
+			errs.validateValue(p, found.path, VALIDATION_POSITION_BODY, found.value, found.exists, false)
+		}
+	}
+	return errs
+}
+
+type jsonValue struct {
+	path   string
+	value  any
+	exists bool
+}
+
+// lookupJsonValues 按参数全称查找数据中的值,数组展开为每个元素;上级不存在时不返回,缺失由上级参数校验
+func lookupJsonValues(data any, segments []fullnameSegment, path string) (values []jsonValue) {
+	if len(segments) == 0 {
+		return []jsonValue{{path: path, value: data, exists: true}}
+	}
+	seg, rest := segments[0], segments[1:]
+	if seg.Name != "" {
+		obj, ok := data.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if path != "" {
+			path += "."
+		}
+		path += seg.Name
+		v, ok := obj[seg.Name]
+		if !ok {
+			if len(rest) == 0 && seg.ArrayDepth == 0 {
+				return []jsonValue{{path: path}}
+			}
+			return nil
+		}
+		data = v
+	}
+	return lookupArrayValues(data, seg.ArrayDepth, rest, path)
+}
+
+func lookupArrayValues(data any, depth int, rest []fullnameSegment, path string) (values []jsonValue) {
+	if depth == 0 {
+		return lookupJsonValues(data, rest, path)
+	}
+	arr, ok := data.([]any)
+	if !ok {
+		return nil
+	}
+	for i, item := range arr {
+		values = append(values, lookupArrayValues(item, depth-1, rest, fmt.Sprintf("%s[%d]", path, i))...)
+	}
+	return values
+}
+
+// validateStrings 校验 query、header 等字符串值
+func (errs *ValidationErrors) validateStrings(p Parameter, values []string, position string) {
+	if len(values) == 0 {
+		errs.validateValue(p, p.Fullname, position, nil, false, true)
+		return
+	}
+	for _, value := range values {
+		errs.validateValue(p, p.Fullname, position, value, true, true)
+	}
+}
+
+func (errs *ValidationErrors) validateValue(p Parameter, path string, position string, value any, exists bool, fromString bool) {
+	if !exists || value == nil {
+		if p.Required {
+			errs.add(p, path, position, VALIDATION_RULE_REQUIRED, "is required")
+		}
+		return
+	}
+	if s, ok := value.(string); ok && s == "" && p.AllowEmptyValue {
+		return
+	}
+	schema := parameter2OpenAPISchema(p)
+	typ := schema.Type.Main()
+	if fromString {
+		converted, ok := convertStringValue(value.(string), typ)
+		if !ok {
+			errs.add(p, path, position, VALIDATION_RULE_TYPE, "must be %s", typ)
+			return
+		}
+		value = converted
+	} else if typ != "" && !isJsonType(value, typ) {
+		errs.add(p, path, position, VALIDATION_RULE_TYPE, "must be %s", typ)
+		return
+	}
+
+	if len(schema.Enum) > 0 {
+		matched := false
+		for _, e := range schema.Enum {
+			if cast.ToString(e) == cast.ToString(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			errs.add(p, path, position, VALIDATION_RULE_ENUM, "must be one of %s", strings.Join(splitEnum(firstNotEmpty(p.Enum, p.Schema.Enum)), ","))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			errs.add(p, path, position, VALIDATION_RULE_MIN_LENGTH, "length must be at least %d", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			errs.add(p, path, position, VALIDATION_RULE_MAX_LENGTH, "length must be at most %d", *schema.MaxLength)
+		}
+		for _, pattern := range []string{p.Schema.Pattern, p.RegExp} {
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs.add(p, path, position, VALIDATION_RULE_PATTERN, "invalid pattern %s: %s", pattern, err.Error())
+				continue
+			}
+			if !re.MatchString(v) {
+				errs.add(p, path, position, VALIDATION_RULE_PATTERN, "must match %s", pattern)
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			errs.add(p, path, position, VALIDATION_RULE_MINIMUM, "must be >= %v", *schema.Minimum)
+		}
+		if min, ok := schema.ExclusiveMinimum.(float64); ok && v <= min {
+			errs.add(p, path, position, VALIDATION_RULE_MINIMUM, "must be > %v", min)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			errs.add(p, path, position, VALIDATION_RULE_MAXIMUM, "must be <= %v", *schema.Maximum)
+		}
+		if max, ok := schema.ExclusiveMaximum.(float64); ok && v >= max {
+			errs.add(p, path, position, VALIDATION_RULE_MAXIMUM, "must be < %v", max)
+		}
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			errs.add(p, path, position, VALIDATION_RULE_MIN_ITEMS, "must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			errs.add(p, path, position, VALIDATION_RULE_MAX_ITEMS, "must have at most %d items", *schema.MaxItems)
+		}
+	}
+}
+
+// convertStringValue query、header 值转换为对应类型,数字统一为 float64 与json解析结果一致
+func convertStringValue(s string, typ string) (value any, ok bool) {
+	switch typ {
+	case OpenAPI_Type_Integer:
+		i, err := strconv.ParseInt(s, 10, 64)
+		return float64(i), err == nil
+	case OpenAPI_Type_Number:
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	case OpenAPI_Type_Boolean:
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	}
+	return s, true
+}
+
+func isJsonType(value any, typ string) bool {
+	switch typ {
+	case OpenAPI_Type_String:
+		_, ok := value.(string)
+		return ok
+	case OpenAPI_Type_Integer:
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case OpenAPI_Type_Number:
+		_, ok := value.(float64)
+		return ok
+	case OpenAPI_Type_Boolean:
+		_, ok := value.(bool)
+		return ok
+	case OpenAPI_Type_Array:
+		_, ok := value.([]any)
+		return ok
+	case OpenAPI_Type_Object:
+		_, ok := value.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
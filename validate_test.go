@@ -0,0 +1,82 @@
+package apidocbuilder_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestRequestValidatorMiddleware(t *testing.T) {
+	validateService := apidocbuilder.Service{
+		Apis: apidocbuilder.Apis{
+			{
+				Method: "POST", Path: "/user/{id}",
+				Query: apidocbuilder.Query{
+					{Fullname: "id", Type: "int", Position: "path", Required: true},
+					{Fullname: "lang", Type: "string", Enum: "zh,en"},
+				},
+				RequestHeader: apidocbuilder.Header{
+					{Fullname: "X-Token", Type: "string", Required: true},
+				},
+				RequestBody: apidocbuilder.Parameters{
+					{Fullname: "name", Type: "string", Required: true, Schema: apidocbuilder.Schema{MaxLength: 4}},
+					{Fullname: "code", Type: "string", RegExp: `^\d+$`},
+					{Fullname: "items[].count", Type: "int", Required: true},
+				},
+			},
+		},
+	}
+	called := false
+	validator := apidocbuilder.NewRequestValidator(validateService)
+	handler := validator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/user/abc?lang=fr", strings.NewReader(`{"name":"tommy","code":"a1","items":[{"count":1},{"count":"2"},{}]}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.False(t, called)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp struct {
+		Errors apidocbuilder.ValidationErrors `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	got := make([]string, 0)
+	for _, e := range resp.Errors {
+		got = append(got, e.Position+":"+e.Path+":"+e.Rule)
+	}
+	require.Equal(t, []string{
+		"path:id:type",
+		"query:lang:enum",
+		"header:X-Token:required",
+		"body:name:maxLength",
+		"body:code:pattern",
+		"body:items[1].count:type",
+		"body:items[2].count:required",
+	}, got)
+	require.Equal(t, "items[].count", resp.Errors[5].Fullname)
+
+	r = httptest.NewRequest(http.MethodPost, "/user/1?lang=zh", strings.NewReader(`{"name":"tom","items":[{"count":1}]}`))
+	r.Header.Set("X-Token", "abc")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	called = false
+	validator.MaxBodySize = 16
+	r = httptest.NewRequest(http.MethodPost, "/user/1?lang=zh", strings.NewReader(`{"name":"tom","items":[{"count":1}]}`))
+	r.Header.Set("X-Token", "abc")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.False(t, called)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,169 @@
+package apidocbuilder
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	VARIABLE_SCOPE_EXAMPLE = "example"
+	VARIABLE_SCOPE_RUNTIME = "runtime" // 脚本运行过程中设置的变量
+	VARIABLE_SCOPE_SERVER  = "server"
+	VARIABLE_SCOPE_SERVICE = "service"
+
+	VARIABLE_ENV_PREFIX = "$env." // {{$env.TOKEN}} 读取进程环境变量,密钥不写入文档
+)
+
+// 变量值中可以再引用变量,限制替换层数防止循环引用
+const variableResolveDepth = 10
+
+var variablePlaceholderReg = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// VariableBuiltin 动态变量,每次替换重新计算
+type VariableBuiltin func() (value string)
+
+// VariableBuiltinsDefault 内置动态变量,名称与 postman 保持一致
+var VariableBuiltinsDefault = map[string]VariableBuiltin{
+	"$timestamp":    func() string { return fmt.Sprintf("%d", time.Now().Unix()) },
+	"$timestampMs":  func() string { return fmt.Sprintf("%d", time.Now().UnixMilli()) },
+	"$isoTimestamp": func() string { return time.Now().UTC().Format(time.RFC3339) },
+	"$uuid":         randomUUID,
+	"$guid":         randomUUID,
+	"$randomInt":    func() string { return fmt.Sprintf("%d", randomInt(1000)) },
+}
+
+type VariableScope struct {
+	Name   string
+	Values map[string]string
+	// 变量名-环境变量名,环境变量存在时覆盖 Values 中的值
+	Envs map[string]string
+}
+
+// VariableResolver 替换 {{name}} 占位符,按作用域顺序查找,靠前的优先;未定义的变量保留原样
+type VariableResolver struct {
+	scopes   []VariableScope
+	Builtins map[string]VariableBuiltin
+	// 读取环境变量,默认为空不读取,避免密钥写入文档;实际发送请求的契约测试、调试代理设置为 os.LookupEnv
+	LookupEnv func(key string) (value string, ok bool)
+}
+
+// NewVariableResolver scopes 优先级从高到低
+func NewVariableResolver(scopes ...VariableScope) (resolver *VariableResolver) {
+	return &VariableResolver{scopes: scopes, Builtins: VariableBuiltinsDefault}
+}
+
+// AddScope 追加优先级最低的作用域
+func (r *VariableResolver) AddScope(name string, values map[string]string) *VariableResolver {
+	r.scopes = append(r.scopes, VariableScope{Name: name, Values: values})
+	return r
+}
+
+// AddVariables 追加优先级最低的作用域,设置了 Env 的变量从环境变量读取
+func (r *VariableResolver) AddVariables(name string, variables Variables) *VariableResolver {
+	scope := VariableScope{Name: name, Values: variables.ToMap(), Envs: make(map[string]string)}
+	for _, variable := range variables {
+		if variable.Env != "" {
+			scope.Envs[variable.Name] = variable.Env
+		}
+	}
+	r.scopes = append(r.scopes, scope)
+	return r
+}
+
+// Lookup 查找顺序:环境变量($env.前缀) > 内置动态变量 > 作用域
+func (r *VariableResolver) Lookup(name string) (value string, ok bool) {
+	if key, isEnv := strings.CutPrefix(name, VARIABLE_ENV_PREFIX); isEnv {
+		if r.LookupEnv == nil {
+			return "", false
+		}
+		return r.LookupEnv(key)
+	}
+	if builtin, ok := r.Builtins[name]; ok {
+		return builtin(), true
+	}
+	for _, scope := range r.scopes {
+		if envKey, ok := scope.Envs[name]; ok && r.LookupEnv != nil {
+			if value, ok := r.LookupEnv(envKey); ok {
+				return value, true
+			}
+		}
+		if value, ok := scope.Values[name]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Resolve 替换字符串中的变量
+func (r *VariableResolver) Resolve(s string) (resolved string) {
+	if r == nil {
+		return s
+	}
+	resolved = s
+	for i := 0; i < variableResolveDepth && strings.Contains(resolved, "{{"); i++ {
+		replaced := false
+		resolved = variablePlaceholderReg.ReplaceAllStringFunc(resolved, func(placeholder string) string {
+			name := variablePlaceholderReg.FindStringSubmatch(placeholder)[1]
+			value, ok := r.Lookup(name)
+			if !ok {
+				return placeholder
+			}
+			replaced = true
+			return value
+		})
+		if !replaced {
+			break
+		}
+	}
+	return resolved
+}
+
+// ResolveMap 替换 map 值中的变量,返回新的 map
+func (r *VariableResolver) ResolveMap(m map[string]string) (resolved map[string]string) {
+	if m == nil {
+		return nil
+	}
+	resolved = make(map[string]string, len(m))
+	for k, v := range m {
+		resolved[k] = r.Resolve(v)
+	}
+	return resolved
+}
+
+// Unresolved 返回字符串中未定义的变量名
+func (r *VariableResolver) Unresolved(s string) (names []string) {
+	for _, match := range variablePlaceholderReg.FindAllStringSubmatch(r.Resolve(s), -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// VariableResolver 接口使用的变量解析器,优先级:案例 > 服务器 > 服务
+func (api Api) VariableResolver(server Server, example Example) (resolver *VariableResolver) {
+	resolver = NewVariableResolver()
+	resolver.AddVariables(VARIABLE_SCOPE_EXAMPLE, example.Variables)
+	resolver.AddVariables(VARIABLE_SCOPE_SERVER, server.Variables)
+	if api.Service != nil {
+		resolver.AddVariables(VARIABLE_SCOPE_SERVICE, api.Service.Variables)
+	}
+	return resolver
+}
+
+func randomUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6], b[8] = (b[6]&0x0f)|0x40, (b[8]&0x3f)|0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func randomInt(max int64) int64 {
+	n, err := rand.Int(rand.Reader, big.NewInt(max))
+	if err != nil {
+		return 0
+	}
+	return n.Int64()
+}
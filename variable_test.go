@@ -0,0 +1,124 @@
+package apidocbuilder_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/apidocbuilder"
+)
+
+func TestVariableResolver(t *testing.T) {
+	resolver := apidocbuilder.NewVariableResolver().
+		AddScope(apidocbuilder.VARIABLE_SCOPE_EXAMPLE, map[string]string{"id": "2"}).
+		AddVariables(apidocbuilder.VARIABLE_SCOPE_SERVICE, apidocbuilder.Variables{
+			{Name: "id", Value: "1"},
+			{Name: "host", Value: "http://{{domain}}"},
+			{Name: "domain", Value: "example.com"},
+			{Name: "token", Value: "******", Env: "TEST_API_TOKEN"},
+		})
+	resolver.LookupEnv = func(key string) (string, bool) {
+		if key == "TEST_API_TOKEN" || key == "SECRET" {
+			return "s3cret", true
+		}
+		return "", false
+	}
+	require.Equal(t, "http://example.com/user/2", resolver.Resolve("{{host}}/user/{{ id }}"))
+	require.Equal(t, "s3cret,s3cret", resolver.Resolve("{{token}},{{$env.SECRET}}"))
+	require.Equal(t, "{{unknown}}", resolver.Resolve("{{unknown}}"))
+	require.Equal(t, []string{"unknown"}, resolver.Unresolved("{{id}}{{unknown}}"))
+	require.Regexp(t, regexp.MustCompile(`^\d{10}$`), resolver.Resolve("{{$timestamp}}"))
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), resolver.Resolve("{{$uuid}}"))
+	require.Regexp(t, regexp.MustCompile(`^\d{1,3}$`), resolver.Resolve("{{$randomInt}}"))
+
+	resolver.LookupEnv = nil
+	require.Equal(t, "******", resolver.Resolve("{{token}}"))
+
+	loop := apidocbuilder.NewVariableResolver().AddScope("loop", map[string]string{"a": "{{a}}"})
+	require.Equal(t, "{{a}}", loop.Resolve("{{a}}"))
+}
+
+func TestSnippetRequestVariables(t *testing.T) {
+	service := &apidocbuilder.Service{
+		Variables: apidocbuilder.Variables{{Name: "version", Value: "v1"}, {Name: "token", Value: "service"}},
+	}
+	api := apidocbuilder.Api{Method: "POST", Path: "/user", Service: service}
+	server := apidocbuilder.Server{URL: "http://{{host}}", Variables: apidocbuilder.Variables{{Name: "host", Value: "localhost:8080"}, {Name: "token", Value: "server"}}}
+	example := apidocbuilder.Example{
+		URL:         "/{{version}}/user",
+		Headers:     map[string]string{"Authorization": "Bearer {{token}}"},
+		RequestBody: `{"name":"{{name}}"}`,
+		Variables:   apidocbuilder.Variables{{Name: "name", Value: "tom"}},
+	}
+	request, err := api.SnippetRequest(server, example)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080/v1/user", request.URL)
+	require.Contains(t, request.Headers, apidocbuilder.SnippetPair{Key: "Authorization", Value: "Bearer server"})
+	require.Equal(t, `{"name":"tom"}`, request.Body)
+
+	api.Examples = apidocbuilder.Examples{&example}
+	service.Servers = apidocbuilder.Servers{server}
+	curl, err := api.CURLExample()
+	require.NoError(t, err)
+	require.Contains(t, curl, "http://localhost:8080/user")
+}
+
+func TestContractRunnerVariables(t *testing.T) {
+	service := apidocbuilder.Service{
+		Variables: apidocbuilder.Variables{{Name: "token", Value: "abc"}},
+		Apis: apidocbuilder.Apis{
+			{
+				Name: "getUser", Method: "GET", Path: "/user/{id}",
+				Examples: apidocbuilder.Examples{
+					{URL: "/user/{{id}}", Headers: map[string]string{"Authorization": "{{token}}"}, Variables: apidocbuilder.Variables{{Name: "id", Value: "1"}}},
+				},
+			},
+		},
+	}
+	var path, authorization string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization = r.URL.Path, r.Header.Get("Authorization")
+	})
+	report, err := apidocbuilder.NewContractRunnerWithHandler(service, handler).Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, report.Passed())
+	require.Equal(t, "/user/1", path)
+	require.Equal(t, "abc", authorization)
+}
+
+func TestVariableEnvNotInDocs(t *testing.T) {
+	t.Setenv("ZZ_DOC_SECRET", "s3cret")
+	service := &apidocbuilder.Service{
+		Variables: apidocbuilder.Variables{{Name: "token", Value: "******", Env: "ZZ_DOC_SECRET"}},
+		Servers:   apidocbuilder.Servers{{Name: "dev", URL: "http://localhost/{{$env.ZZ_DOC_SECRET}}"}},
+	}
+	api := apidocbuilder.Api{Method: "GET", Path: "/user", Service: service,
+		Query: apidocbuilder.Query{{Fullname: "token", Example: "{{token}}"}},
+	}
+	api.Examples = apidocbuilder.Examples{{Headers: map[string]string{"Authorization": "Bearer {{token}}"}}}
+
+	require.NotContains(t, apidocbuilder.NewVariableResolver().Resolve("{{$env.ZZ_DOC_SECRET}}"), "s3cret")
+	curl, err := api.CURLExampleWithEnvironment("")
+	require.NoError(t, err)
+	require.NotContains(t, curl, "s3cret")
+	snippets, err := api.Snippets(service.Servers[0], *api.Examples[0])
+	require.NoError(t, err)
+	for _, snippet := range snippets {
+		require.NotContains(t, snippet.Code, "s3cret", snippet.Language)
+	}
+	example, err := api.ExampleWithEnvironment("")
+	require.NoError(t, err)
+	require.NotContains(t, example.URL, "s3cret")
+
+	// 契约测试实际发送请求,读取环境变量
+	var authorization string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	})
+	runService := apidocbuilder.Service{Variables: service.Variables, Apis: apidocbuilder.Apis{{Name: "getUser", Method: "GET", Path: "/user", Examples: api.Examples}}}
+	_, err = apidocbuilder.NewContractRunnerWithHandler(runService, handler).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Bearer s3cret", authorization)
+}